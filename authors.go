@@ -0,0 +1,262 @@
+package readability
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+// Author is an author of the article.
+type Author struct {
+	Name string `json:"name"`
+	// URL is the page of the author, e.g. their profile on the site.
+	URL string `json:"url,omitempty"`
+	// Role is the contribution of the author to the article: "author",
+	// "editor", "photographer", "illustrator" or "translator".
+	Role string `json:"role,omitempty"`
+}
+
+var (
+	rxBylineRole   = regexp.MustCompile(`(?i)(?:^|\s)(?:(written|edited|photos?|photographs?|photography|illustrations?|illustrated|translated|reported|reporting|words|text|story)\s+)?by\s+`)
+	rxBylinePrefix = regexp.MustCompile(`^(?:(?i:par|von|por)|door|av|af|di)\s+`)
+	rxBylineDate   = regexp.MustCompile(`(?i)(?:\b(?:published|posted|updated|last updated)\b|(?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+\d{1,2}(?:st|nd|rd|th)?,?\s+\d{4}|\d{1,2}\s+(?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+\d{4}|\d{4}-\d{2}-\d{2}|\d{1,2}/\d{1,2}/\d{2,4}).*$`)
+	rxBylineURL    = regexp.MustCompile(`\(\s*(https?://[^\s)]+)\s*\)`)
+	rxAuthorSep    = regexp.MustCompile(`(?i)\s*(?:[,;、]|\s(?:and|&|et|und)\s)\s*`)
+	credentials    = sliceToMap("ma", "md", "ms", "mph", "mba", "phd", "rn", "jd", "do")
+	abbreviations  = sliceToMap("jr", "sr", "st", "dr")
+)
+
+// getAuthors returns the authors of the article. The authors in JSON-LD are
+// preferred, since they are structured, otherwise the byline is split into
+// authors. The URLs of the authors are taken from the links of the byline,
+// the links with rel=author and the article:author <meta> tag.
+func (ps *parseState) getAuthors(linkedData *LinkedData, byline string, bylineLinks, relLinks []Author) []Author {
+	var authors []Author
+	if linkedData != nil {
+		for _, entity := range linkedData.Authors {
+			authors = appendAuthor(authors, Author{
+				Name: entity.Name,
+				URL:  toAbsoluteURI(entity.URL, ps.documentURI),
+				Role: "author",
+			})
+		}
+	}
+
+	if len(authors) == 0 {
+		for _, author := range parseByline(byline) {
+			authors = appendAuthor(authors, author)
+		}
+	}
+
+	if len(authors) == 0 {
+		for _, link := range relLinks {
+			authors = appendAuthor(authors, Author{Name: link.Name, URL: link.URL, Role: "author"})
+		}
+	}
+
+	// Match the links with the authors by name. The links of the byline
+	// and the ones without a name may be about a single author, even if
+	// their text isn't the name, e.g. "View profile".
+	var candidates []string
+	for i, links := range [][]Author{bylineLinks, relLinks} {
+		isByline := i == 0
+		for _, link := range links {
+			matched := false
+			for j := range authors {
+				if link.Name != "" && strings.EqualFold(authors[j].Name, link.Name) {
+					if authors[j].URL == "" {
+						authors[j].URL = link.URL
+					}
+					matched = true
+				}
+			}
+
+			if !matched && (isByline || link.Name == "") && indexOf(candidates, link.URL) == -1 {
+				candidates = append(candidates, link.URL)
+			}
+		}
+	}
+
+	if metaURL := ps.rawMetadata["article:author"]; isValidURL(metaURL) && indexOf(candidates, metaURL) == -1 {
+		candidates = append(candidates, metaURL)
+	}
+	if len(authors) == 1 && authors[0].URL == "" && len(candidates) == 1 {
+		authors[0].URL = candidates[0]
+	}
+
+	return authors
+}
+
+// getAuthorLinks returns the <a> and <link> elements of root that have a
+// rel=author, or all the links of root if it's a byline, as authors whose
+// name is the text of the link.
+func (ps *parseState) getAuthorLinks(root *html.Node, isByline bool) []Author {
+	selector := `a[rel~="author"][href], link[rel~="author"][href]`
+	if isByline {
+		selector = "a[href]"
+	}
+
+	var links []Author
+	for _, node := range dom.QuerySelectorAll(root, selector) {
+		href := toAbsoluteURI(strings.TrimSpace(dom.GetAttribute(node, "href")), ps.documentURI)
+		if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+			continue
+		}
+
+		links = append(links, Author{
+			Name: normalizeWhitespace(dom.TextContent(node)),
+			URL:  href,
+		})
+	}
+	return links
+}
+
+// parseByline splits a byline into its authors, e.g. "Written by Jane Doe
+// and John Smith Edited by Alice Martin, May 3, 2024" has the authors Jane
+// Doe and John Smith, and the editor Alice Martin. The prefixes like "By"
+// and the dates are removed.
+func parseByline(byline string) []Author {
+	byline = normalizeWhitespace(byline)
+	byline = rxBylinePrefix.ReplaceAllString(byline, "")
+
+	// Split the byline into the parts of each role
+	var authors []Author
+	addNames := func(text, role string) {
+		for _, name := range splitAuthorNames(text) {
+			author := Author{Name: name, Role: role}
+			if match := rxBylineURL.FindStringSubmatchIndex(name); match != nil {
+				author.URL = name[match[2]:match[3]]
+				author.Name = trimAuthorName(name[:match[0]] + name[match[1]:])
+			}
+			if author.Name != "" {
+				authors = appendAuthor(authors, author)
+			}
+		}
+	}
+
+	start, role := 0, "author"
+	for _, match := range rxBylineRole.FindAllStringSubmatchIndex(byline, -1) {
+		addNames(byline[start:match[0]], role)
+		role = "author"
+		if match[2] >= 0 {
+			role = bylineRole(byline[match[2]:match[3]])
+		}
+		start = match[1]
+	}
+	addNames(byline[start:], role)
+
+	return authors
+}
+
+// splitAuthorNames splits text into names, which are separated by commas or
+// by "and". The separators inside parentheses are ignored, and text isn't
+// split if any part is a single word, since it's likely a name written as
+// "Last, First" or a name like "Mac & i".
+func splitAuthorNames(text string) []string {
+	text = trimAuthorName(rxBylineDate.ReplaceAllString(text, ""))
+	if text == "" {
+		return nil
+	}
+
+	var parts []string
+	start := 0
+	for _, loc := range rxAuthorSep.FindAllStringIndex(text, -1) {
+		if before := text[:loc[0]]; strings.Count(before, "(") > strings.Count(before, ")") {
+			continue
+		}
+		parts = append(parts, text[start:loc[0]])
+		start = loc[1]
+	}
+	parts = append(parts, text[start:])
+
+	var names []string
+	for _, part := range parts {
+		part = trimAuthorName(part)
+		words := strings.Fields(part)
+		if len(words) == 0 {
+			continue
+		}
+
+		// Credentials that follow a name, e.g. "Jane Doe, MD" or "Jane Doe,
+		// Ph.D."
+		if _, isCredential := credentials[strings.ReplaceAll(strings.ToLower(words[0]), ".", "")]; isCredential {
+			continue
+		}
+		if len(words) == 1 && !containsCJK(part) {
+			return []string{text}
+		}
+		names = append(names, part)
+	}
+	return names
+}
+
+// bylineRole returns the role of the authors introduced by word, e.g.
+// "photographer" for "Photos by".
+func bylineRole(word string) string {
+	switch word = strings.ToLower(word); {
+	case word == "edited":
+		return "editor"
+	case strings.HasPrefix(word, "photo"):
+		return "photographer"
+	case strings.HasPrefix(word, "illustrat"):
+		return "illustrator"
+	case word == "translated":
+		return "translator"
+	default:
+		return "author"
+	}
+}
+
+// trimAuthorName removes the whitespace and punctuation around name. A
+// final period is kept if it ends an abbreviation, e.g. "John Smith Jr.",
+// "Jane Doe, Ph.D." or "Harry S.", since it's part of the name.
+func trimAuthorName(name string) string {
+	isPunct := func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(",;:|-–—·•/", r)
+	}
+
+	name = strings.TrimLeftFunc(name, func(r rune) bool { return isPunct(r) || r == '.' })
+	for {
+		name = strings.TrimRightFunc(name, isPunct)
+		words := strings.Fields(name)
+		if !strings.HasSuffix(name, ".") || isAbbreviation(words[len(words)-1]) {
+			return name
+		}
+		name = strings.TrimSuffix(name, ".")
+	}
+}
+
+// isAbbreviation reports whether word, which ends with a period, is an
+// abbreviation, i.e. an initial, a suffix like "Jr." or a credential.
+func isAbbreviation(word string) bool {
+	word = strings.ToLower(strings.TrimSuffix(word, "."))
+	if strings.Contains(word, ".") || utf8.RuneCountInString(word) == 1 {
+		return true
+	}
+	_, isSuffix := abbreviations[word]
+	_, isCredential := credentials[word]
+	return isSuffix || isCredential
+}
+
+// appendAuthor appends author to authors, unless its name is empty or
+// there's already an author with the same name.
+func appendAuthor(authors []Author, author Author) []Author {
+	if author.Name == "" {
+		return authors
+	}
+	for _, existing := range authors {
+		if strings.EqualFold(existing.Name, author.Name) {
+			return authors
+		}
+	}
+	return append(authors, author)
+}
+
+// containsCJK reports whether text contains a Chinese or Japanese character.
+func containsCJK(text string) bool {
+	return strings.IndexFunc(text, isCJK) >= 0
+}
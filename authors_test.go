@@ -0,0 +1,117 @@
+package readability
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseByline(t *testing.T) {
+	scenarios := []struct {
+		byline string
+		want   []Author
+	}{
+		{"By Jane Doe", []Author{{Name: "Jane Doe", Role: "author"}}},
+		{"Par Sébastien Farcis", []Author{{Name: "Sébastien Farcis", Role: "author"}}},
+		{"By GILLIAN MOHNEY March 11, 2015 3:46 PM", []Author{{Name: "GILLIAN MOHNEY", Role: "author"}}},
+		{"by Lucas Nolan22 Dec 2016651", []Author{{Name: "Lucas Nolan", Role: "author"}}},
+		{"Jane Doe | Updated 2024-05-03", []Author{{Name: "Jane Doe", Role: "author"}}},
+		{"Stella Kim, Jennifer Jett", []Author{
+			{Name: "Stella Kim", Role: "author"},
+			{Name: "Jennifer Jett", Role: "author"},
+		}},
+		{"By Jane Doe and John Smith", []Author{
+			{Name: "Jane Doe", Role: "author"},
+			{Name: "John Smith", Role: "author"},
+		}},
+		{"Written by Rob Ewaschuk Edited by Betsy Beyer", []Author{
+			{Name: "Rob Ewaschuk", Role: "author"},
+			{Name: "Betsy Beyer", Role: "editor"},
+		}},
+		{"By Jane Doe. Photographs by John Smith", []Author{
+			{Name: "Jane Doe", Role: "author"},
+			{Name: "John Smith", Role: "photographer"},
+		}},
+		{"By Brenda Goodman, MA", []Author{{Name: "Brenda Goodman", Role: "author"}}},
+		{"By Jane Doe, Ph.D.", []Author{{Name: "Jane Doe", Role: "author"}}},
+		{"By John Smith Jr.", []Author{{Name: "John Smith Jr.", Role: "author"}}},
+		{"By Harry S. Truman.", []Author{{Name: "Harry S. Truman", Role: "author"}}},
+		{"Di Maio Luigi", []Author{{Name: "Di Maio Luigi", Role: "author"}}},
+		{"di Luigi Di Maio", []Author{{Name: "Luigi Di Maio", Role: "author"}}},
+		{"Von Max Mustermann", []Author{{Name: "Max Mustermann", Role: "author"}}},
+		{"Bradley M. Kuhn (http://ebb.org/bkuhn/)", []Author{{Name: "Bradley M. Kuhn", URL: "http://ebb.org/bkuhn/", Role: "author"}}},
+		{"Jong, Michiel de", []Author{{Name: "Jong, Michiel de", Role: "author"}}},
+		{"Mac & i", []Author{{Name: "Mac & i", Role: "author"}}},
+		{"Martin Untersinger (avec Damien Leloup et Morgane Tual)", []Author{{Name: "Martin Untersinger (avec Damien Leloup et Morgane Tual)", Role: "author"}}},
+		{"April 28, 2019 at 6:01 am", nil},
+		{"", nil},
+	}
+
+	for _, scenario := range scenarios {
+		if got := parseByline(scenario.byline); !reflect.DeepEqual(got, scenario.want) {
+			t.Errorf("%q\nwant: %+v\ngot : %+v", scenario.byline, scenario.want, got)
+		}
+	}
+}
+
+func Test_Article_Authors(t *testing.T) {
+	const text = `<p>The council met on Tuesday evening to discuss the new budget, which
+includes more money for public schools, libraries and the repair of old bridges.</p>`
+
+	scenarios := []struct {
+		name string
+		html string
+		want []Author
+	}{
+		{
+			name: "json-ld authors",
+			html: `<html><head><script type="application/ld+json">{"@context": "https://schema.org", "@type": "NewsArticle",
+"author": [{"@type": "Person", "name": "Jane Doe", "url": "/authors/jane"}, {"@type": "Person", "name": "John Smith"}]}</script>
+</head><body><article>` + text + `</article></body></html>`,
+			want: []Author{
+				{Name: "Jane Doe", URL: "http://fakehost/authors/jane", Role: "author"},
+				{Name: "John Smith", Role: "author"},
+			},
+		},
+		{
+			name: "byline links",
+			html: `<html><body><article><p class="byline">By <a href="/authors/jane">Jane Doe</a> and <a href="/authors/john">John Smith</a></p>` + text + `</article></body></html>`,
+			want: []Author{
+				{Name: "Jane Doe", URL: "http://fakehost/authors/jane", Role: "author"},
+				{Name: "John Smith", URL: "http://fakehost/authors/john", Role: "author"},
+			},
+		},
+		{
+			name: "article:author url",
+			html: `<html><head><meta name="author" content="Jane Doe"><meta property="article:author" content="https://example.com/jane"></head>
+<body><article>` + text + `</article></body></html>`,
+			want: []Author{{Name: "Jane Doe", URL: "https://example.com/jane", Role: "author"}},
+		},
+		{
+			name: "rel author link without byline",
+			html: `<html><body><article>` + text + `</article><footer><a rel="author" href="/authors/jane">Jane Doe</a></footer></body></html>`,
+			want: []Author{{Name: "Jane Doe", URL: "http://fakehost/authors/jane", Role: "author"}},
+		},
+		{
+			name: "rel author link of another name",
+			html: `<html><head><meta name="author" content="Jane Doe"></head><body><article>` + text + `</article>
+<footer><a rel="author" href="/authors/john">John Smith</a></footer></body></html>`,
+			want: []Author{{Name: "Jane Doe", Role: "author"}},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			parser := NewParser()
+			parser.CharThresholds = 0
+			article, err := parser.Parse(strings.NewReader(scenario.html), fakeHostURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(article.Authors, scenario.want) {
+				t.Errorf("authors\nwant: %+v\ngot : %+v", scenario.want, article.Authors)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package readability
+
+import (
+	"bytes"
+	"os"
+	fp "path/filepath"
+	"testing"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+// loadBenchmarkPages parses the source of the test pages with the
+// specified names, or of every test page if names is empty.
+func loadBenchmarkPages(b *testing.B, names ...string) []*html.Node {
+	if len(names) == 0 {
+		testItems, err := os.ReadDir("test-pages")
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, item := range testItems {
+			if item.IsDir() {
+				names = append(names, item.Name())
+			}
+		}
+	}
+
+	docs := make([]*html.Node, 0, len(names))
+	for _, name := range names {
+		content, err := os.ReadFile(fp.Join("test-pages", name, "source.html"))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		doc, err := dom.Parse(bytes.NewReader(content))
+		if err != nil {
+			b.Fatal(err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func benchmarkParser(b *testing.B, docs []*html.Node) {
+	parser := NewParser()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, doc := range docs {
+			if _, err := parser.ParseDocument(doc, fakeHostURL); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkParser(b *testing.B) {
+	benchmarkParser(b, loadBenchmarkPages(b, "wikipedia-2"))
+}
+
+func BenchmarkParser_corpus(b *testing.B) {
+	benchmarkParser(b, loadBenchmarkPages(b))
+}
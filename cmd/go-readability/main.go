@@ -29,6 +29,7 @@ const index = `<!DOCTYPE HTML>
    <p><label for="url">URL </label><input type="url" name="url" style="width:90%"></p>
    <p><input type="checkbox" name="text" value="true">text only</p>
    <p><input type="checkbox" name="metadata" value="true">only get the page's metadata</p>
+   <p><label for="format">Format </label><select name="format"><option value="html">HTML</option><option value="text">Text</option><option value="markdown">Markdown</option><option value="json">JSON</option><option value="metadata">Metadata</option></select></p>
   </fieldset>
   <p><input type="submit"></p>
  </form>
@@ -45,6 +46,7 @@ func main() {
 	}
 
 	rootCmd.Flags().StringP("http", "l", "", "start the http server at the specified address")
+	rootCmd.Flags().StringP("format", "f", "html", "output format: html, text, markdown, json or metadata")
 	rootCmd.Flags().BoolP("metadata", "m", false, "only print the page's metadata")
 	rootCmd.Flags().BoolP("text", "t", false, "only print the page's text")
 	rootCmd.Flags().BoolP("verbose", "v", false, "enable verbose logging")
@@ -65,11 +67,13 @@ func rootCmdHandler(cmd *cobra.Command, args []string) {
 	}
 
 	// Get cmd parameter
+	format, _ := cmd.Flags().GetString("format")
 	metadataOnly, _ := cmd.Flags().GetBool("metadata")
 	textOnly, _ := cmd.Flags().GetBool("text")
 	verbose, _ := cmd.Flags().GetBool("verbose")
+	format = outputFormat(format, metadataOnly, textOnly)
 	if len(args) > 0 {
-		content, err := getContent(args[0], metadataOnly, textOnly, verbose)
+		content, err := getContent(args[0], format, verbose)
 		if err != nil {
 			log.Fatalln(err)
 		}
@@ -83,6 +87,7 @@ func rootCmdHandler(cmd *cobra.Command, args []string) {
 func httpHandler(w http.ResponseWriter, r *http.Request) {
 	metadataOnly, _ := strconv.ParseBool(r.URL.Query().Get("metadata"))
 	textOnly, _ := strconv.ParseBool(r.URL.Query().Get("text"))
+	format := outputFormat(r.URL.Query().Get("format"), metadataOnly, textOnly)
 	url := r.URL.Query().Get("url")
 	if url == "" {
 		if _, err := w.Write([]byte(index)); err != nil {
@@ -92,16 +97,19 @@ func httpHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	} else {
 		log.Println("process URL", url)
-		content, err := getContent(url, metadataOnly, textOnly, false)
+		content, err := getContent(url, format, false)
 		if err != nil {
 			log.Println(err)
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		if metadataOnly {
+		switch format {
+		case "metadata", "json":
 			w.Header().Set("Content-Type", "application/json")
-		} else if textOnly {
+		case "text":
 			w.Header().Set("Content-Type", "text/plain")
+		case "markdown":
+			w.Header().Set("Content-Type", "text/markdown")
 		}
 		if _, err := w.Write([]byte(content)); err != nil {
 			log.Println(err)
@@ -111,11 +119,33 @@ func httpHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func getContent(srcPath string, metadataOnly, textOnly, verbose bool) (string, error) {
+// outputFormat resolves the output format, giving the legacy --metadata
+// and --text flags precedence over the format name.
+func outputFormat(format string, metadataOnly, textOnly bool) string {
+	switch {
+	case metadataOnly:
+		return "metadata"
+	case textOnly:
+		return "text"
+	case format == "":
+		return "html"
+	default:
+		return strings.ToLower(format)
+	}
+}
+
+func getContent(srcPath, format string, verbose bool) (string, error) {
+	switch format {
+	case "html", "text", "markdown", "json", "metadata":
+	default:
+		return "", fmt.Errorf("unknown output format: %q", format)
+	}
+
 	// Open or fetch web page that will be parsed
 	var (
-		pageURL   *nurl.URL
-		srcReader io.Reader
+		pageURL     *nurl.URL
+		srcReader   io.Reader
+		contentType string
 	)
 
 	if _, isURL := validateURL(srcPath); isURL {
@@ -127,6 +157,7 @@ func getContent(srcPath string, metadataOnly, textOnly, verbose bool) (string, e
 
 		pageURL = resp.Request.URL
 		srcReader = resp.Body
+		contentType = resp.Header.Get("Content-Type")
 	} else {
 		srcFile, err := os.Open(srcPath)
 		if err != nil {
@@ -151,19 +182,25 @@ func getContent(srcPath string, metadataOnly, textOnly, verbose bool) (string, e
 	parser.Debug = verbose
 
 	// Get readable content from the reader
-	article, err := parser.Parse(buf, pageURL)
+	article, err := parser.ParseWithContentType(buf, contentType, pageURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse page: %v", err)
 	}
 
 	// Return the article (or its metadata)
-	if metadataOnly {
+	switch format {
+	case "metadata":
 		metadata := map[string]interface{}{
-			"title":   article.Title,
-			"byline":  article.Byline,
-			"excerpt": article.Excerpt,
-			"image":   article.Image,
-			"favicon": article.Favicon,
+			"title":         article.Title,
+			"byline":        article.Byline,
+			"excerpt":       article.Excerpt,
+			"siteName":      article.SiteName,
+			"image":         article.Image,
+			"favicon":       article.Favicon,
+			"language":      article.Language,
+			"publishedTime": article.PublishedTime,
+			"modifiedTime":  article.ModifiedTime,
+			"length":        article.Length,
 		}
 
 		prettyJSON, err := json.MarshalIndent(&metadata, "", "    ")
@@ -172,13 +209,24 @@ func getContent(srcPath string, metadataOnly, textOnly, verbose bool) (string, e
 		}
 
 		return string(prettyJSON), nil
-	}
+	case "json":
+		// Don't escape the HTML of the content, so it stays readable
+		buf := bytes.NewBuffer(nil)
+		encoder := json.NewEncoder(buf)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "    ")
+		if err := encoder.Encode(&article); err != nil {
+			return "", fmt.Errorf("failed to write article: %v", err)
+		}
 
-	if textOnly {
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+	case "text":
 		return article.TextContent, nil
+	case "markdown":
+		return article.Markdown(), nil
+	default:
+		return article.Content, nil
 	}
-
-	return article.Content, nil
 }
 
 func validateURL(path string) (*nurl.URL, bool) {
@@ -0,0 +1,201 @@
+package readability
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+var (
+	rxDateClass     = regexp.MustCompile(`(?i)date|time|posted|published|byline|meta`)
+	rxPublishClass  = regexp.MustCompile(`(?i)publish|posted|pubdate|created|dateline`)
+	rxModifiedClass = regexp.MustCompile(`(?i)modif|updated|edited`)
+	rxDateNegative  = regexp.MustCompile(`(?i)comment|footer|aside|sidebar|related|recommend|widget|reply|cite|citation|reference|accessdate|bday|birth|death`)
+	rxDateInText    = regexp.MustCompile(`(?i)\b(?:jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?\s+\d{1,2}(?:st|nd|rd|th)?,?\s+\d{4}(?:,?\s+(?:at\s+)?\d{1,2}:\d{2}(?:\s*[ap]\.?m\.?)?)?|\b\d{1,2}\s+(?:jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?\s+\d{4}|\b\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2})?)?|\b\d{4}/\d{1,2}/\d{1,2}\b|\d{4}年\d{1,2}月\d{1,2}日`)
+	rxDateInURL     = regexp.MustCompile(`/((?:19|20)\d{2})/(0?[1-9]|1[0-2])/(0?[1-9]|[12]\d|3[01])(?:/|$)`)
+	rxDateTimezone  = regexp.MustCompile(`\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?\s*(?:Z|[+-]\d{2}:?\d{2})$`)
+)
+
+// dateCandidate is a date found in the page that may be the publication
+// date of the article.
+type dateCandidate struct {
+	date   time.Time
+	source string
+	score  int
+}
+
+// discoverPublishedTime looks for the publication date of the article in
+// the page itself, for the pages that don't have it in their metadata. The
+// dates are taken from the elements with itemprop=datePublished, the <time>
+// elements, the datelines, i.e. the short texts like "Posted on May 17,
+// 2024" near the byline, and the URL of the page. Each date is scored by
+// how likely it is to be the publication date, and the best one is returned
+// with its source. It must be called before the document is modified by the
+// extraction of the content.
+func (ps *parseState) discoverPublishedTime(metadata map[string]string) (*time.Time, string) {
+	loc := ps.documentTimezone(metadata)
+
+	var candidates []dateCandidate
+	add := func(node *html.Node, value, source string, score int) {
+		date := ps.parseDateIn(value, loc)
+		if date == nil || date.Year() < 1990 || date.After(time.Now().Add(48*time.Hour)) {
+			return
+		}
+
+		// The dates of the comments, of the related articles or of the
+		// citations are usually not the one of the article
+		for parent := node; parent != nil && parent.Type == html.ElementNode; parent = parent.Parent {
+			tagName := dom.TagName(parent)
+			if tagName == "footer" || tagName == "aside" ||
+				rxDateNegative.MatchString(dom.ClassName(parent)+" "+dom.ID(parent)) {
+				score -= 25
+				break
+			}
+		}
+
+		if score > 0 {
+			candidates = append(candidates, dateCandidate{date: *date, source: source, score: score})
+		}
+	}
+
+	for _, node := range dom.GetElementsByTagName(ps.doc, "*") {
+		itemprop := dom.GetAttribute(node, "itemprop")
+		classAndID := dom.ClassName(node) + " " + dom.ID(node)
+		matchString := classAndID
+		if node.Parent != nil {
+			matchString += " " + dom.ClassName(node.Parent) + " " + dom.ID(node.Parent)
+		}
+
+		switch {
+		case strings.Contains(itemprop, "datePublished"):
+			add(node, microdataValue(node), "itemprop", 50)
+
+		case dom.TagName(node) == "time":
+			if strings.Contains(itemprop, "dateModified") || rxModifiedClass.MatchString(matchString) {
+				continue
+			}
+
+			value := dom.GetAttribute(node, "datetime")
+			if value == "" {
+				value = rxDateInText.FindString(dom.TextContent(node))
+			}
+
+			score := 30
+			if dom.HasAttribute(node, "pubdate") || rxPublishClass.MatchString(matchString) {
+				score += 10
+			}
+			add(node, value, "<time>", score)
+
+		case rxDateClass.MatchString(classAndID):
+			if rxModifiedClass.MatchString(classAndID) {
+				continue
+			}
+
+			text := normalizeWhitespace(dom.TextContent(node))
+			if charCount(text) > 200 {
+				continue
+			}
+
+			loc := rxDateInText.FindStringIndex(text)
+			if loc == nil || rxModifiedClass.MatchString(text[:loc[0]]) {
+				continue
+			}
+
+			score := 20
+			if rxPublishClass.MatchString(matchString) || rxPublishClass.MatchString(text[:loc[0]]) {
+				score += 10
+			}
+			add(node, text[loc[0]:loc[1]], "dateline", score)
+		}
+	}
+
+	// The date in the URL is only a day, but it confirms the dates of the
+	// same day
+	if ps.documentURI != nil {
+		if match := rxDateInURL.FindStringSubmatch(ps.documentURI.Path); match != nil {
+			year, _ := strconv.Atoi(match[1])
+			month, _ := strconv.Atoi(match[2])
+			day, _ := strconv.Atoi(match[3])
+			urlDate := fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+
+			for i := range candidates {
+				if candidates[i].date.Format("2006-01-02") == urlDate {
+					candidates[i].score += 10
+				}
+			}
+			add(nil, urlDate, "url", 10)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, ""
+	}
+
+	// The best candidate wins, or the first one in the page if it's a tie
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	best := candidates[0]
+	ps.logf("discovered published time %v in %s with score %d\n", best.date, best.source, best.score)
+	return &best.date, best.source
+}
+
+// documentTimezone returns the timezone of the first date of the page that
+// has one, in its metadata or in the datetime of its <time> elements, so
+// it can be used for the dates that don't have one. It returns nil if no
+// date of the page has a timezone.
+func (ps *parseState) documentTimezone(metadata map[string]string) *time.Location {
+	values := []string{metadata["modifiedTime"]}
+
+	var keys []string
+	for key := range ps.rawMetadata {
+		if strings.Contains(key, "time") || strings.Contains(key, "date") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values = append(values, ps.rawMetadata[key])
+	}
+
+	for _, node := range dom.GetElementsByTagName(ps.doc, "time") {
+		values = append(values, dom.GetAttribute(node, "datetime"))
+	}
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if !rxDateTimezone.MatchString(value) {
+			continue
+		}
+		if date, err := dateparse.ParseAny(value); err == nil {
+			return date.Location()
+		}
+	}
+	return nil
+}
+
+// parseDateIn is like getParsedDate, but the dates without a timezone are
+// in loc, if it's not nil.
+func (ps *parseState) parseDateIn(dateStr string, loc *time.Location) *time.Time {
+	if dateStr = strings.TrimSpace(dateStr); dateStr == "" {
+		return nil
+	}
+	if loc == nil {
+		return ps.getParsedDate(dateStr)
+	}
+
+	d, err := dateparse.ParseIn(dateStr, loc)
+	if err != nil {
+		ps.logf("failed to parse date \"%s\": %v\n", dateStr, err)
+		return nil
+	}
+	return &d
+}
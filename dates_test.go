@@ -0,0 +1,113 @@
+package readability
+
+import (
+	nurl "net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Article_PublishedTime(t *testing.T) {
+	const text = `<p>The council met on Tuesday evening to discuss the new budget, which
+includes more money for public schools, libraries and the repair of old bridges.</p>`
+
+	scenarios := []struct {
+		name       string
+		url        string
+		html       string
+		want       string
+		wantSource string
+	}{
+		{
+			name:       "metadata",
+			html:       `<html><head><meta property="article:published_time" content="2024-05-03T08:00:00Z"></head><body><article><time datetime="2024-04-01">April 1</time>` + text + `</article></body></html>`,
+			want:       "2024-05-03T08:00:00Z",
+			wantSource: "article:published_time",
+		},
+		{
+			name:       "itemprop",
+			html:       `<html><body><article><meta itemprop="datePublished" content="2024-05-03T08:00:00+02:00"><time datetime="2024-04-01">April 1</time>` + text + `</article></body></html>`,
+			want:       "2024-05-03T08:00:00+02:00",
+			wantSource: "itemprop",
+		},
+		{
+			name:       "time",
+			html:       `<html><body><article><time class="updated" datetime="2024-06-01T10:00:00Z">June 1</time><time datetime="2024-05-03T08:00:00Z">May 3</time>` + text + `</article></body></html>`,
+			want:       "2024-05-03T08:00:00Z",
+			wantSource: "<time>",
+		},
+		{
+			name:       "dateline",
+			html:       `<html><body><article><p class="post-meta">Posted on May 3, 2024 by Jane Doe</p>` + text + `</article></body></html>`,
+			want:       "2024-05-03T00:00:00Z",
+			wantSource: "dateline",
+		},
+		{
+			name: "timezone of the document",
+			html: `<html><head><meta property="article:modified_time" content="2024-05-04T10:00:00-07:00"></head>
+<body><article><p class="post-meta">Posted on May 3, 2024 at 9:30 am</p>` + text + `</article></body></html>`,
+			want:       "2024-05-03T09:30:00-07:00",
+			wantSource: "dateline",
+		},
+		{
+			name: "comment dates are ignored",
+			html: `<html><body><article>` + text + `<div class="comments"><time datetime="2024-06-01T10:00:00Z">June 1</time></div></article>
+<p class="post-meta">Posted on May 3, 2024</p></body></html>`,
+			want:       "2024-05-03T00:00:00Z",
+			wantSource: "dateline",
+		},
+		{
+			name:       "url",
+			url:        "http://fakehost/2024/05/03/budget.html",
+			html:       `<html><body><article>` + text + `</article></body></html>`,
+			want:       "2024-05-03T00:00:00Z",
+			wantSource: "url",
+		},
+		{
+			name:       "url confirms a date",
+			url:        "http://fakehost/2024/05/03/budget.html",
+			html:       `<html><body><article><p class="post-meta">Posted on April 1, 2024</p><time datetime="2024-05-03T08:00:00Z">May 3</time>` + text + `</article></body></html>`,
+			want:       "2024-05-03T08:00:00Z",
+			wantSource: "<time>",
+		},
+		{
+			name: "invalid metadata",
+			html: `<html><head><meta property="article:published_time" content="not a date"><meta property="article:modified_time" content="yesterday"></head>
+<body><article>` + text + `</article></body></html>`,
+		},
+		{
+			name: "none",
+			html: `<html><body><article>` + text + `</article></body></html>`,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			pageURL := fakeHostURL
+			if scenario.url != "" {
+				pageURL, _ = nurl.ParseRequestURI(scenario.url)
+			}
+
+			parser := NewParser()
+			parser.CharThresholds = 0
+			article, err := parser.Parse(strings.NewReader(scenario.html), pageURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got string
+			if article.PublishedTime != nil {
+				got = article.PublishedTime.Format(time.RFC3339)
+			}
+			if got != scenario.want {
+				t.Errorf("published time, want %q got %q", scenario.want, got)
+			}
+			if source := article.MetadataSources["publishedTime"]; source != scenario.wantSource {
+				t.Errorf("source, want %q got %q", scenario.wantSource, source)
+			}
+			if source, exists := article.MetadataSources["modifiedTime"]; exists && article.ModifiedTime == nil {
+				t.Errorf("source of missing modified time: %q", source)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package readability
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+// Diagnostics is a structured report of how the article content was found.
+// It is only collected when Parser.CollectDiagnostics is enabled.
+type Diagnostics struct {
+	// Attempts lists every pass of the content extraction, in order. The
+	// parser retries with less strict flags when an attempt doesn't find
+	// enough text.
+	Attempts []AttemptReport `json:"attempts"`
+	// SelectedAttempt is the index in Attempts of the attempt whose content
+	// was used for the article, or -1 if no content was found.
+	SelectedAttempt int `json:"selectedAttempt"`
+}
+
+// AttemptReport describes a single pass of the content extraction.
+type AttemptReport struct {
+	// StripUnlikelys, UseWeightClasses and CleanConditionally are the flags
+	// that were enabled during this attempt.
+	StripUnlikelys     bool `json:"stripUnlikelys"`
+	UseWeightClasses   bool `json:"useWeightClasses"`
+	CleanConditionally bool `json:"cleanConditionally"`
+	// DroppedFlag is the name of the flag that was disabled for the next
+	// attempt because this one didn't find enough text.
+	DroppedFlag string `json:"droppedFlag,omitempty"`
+	// TextLength is the number of characters of the extracted content.
+	TextLength int `json:"textLength"`
+	// Candidates are the top scored candidates for the article content,
+	// ordered by their score.
+	Candidates []CandidateReport `json:"candidates"`
+	// Removed lists the nodes that were removed from the article content
+	// by the conditional cleaning.
+	Removed []RemovedNodeReport `json:"removed"`
+}
+
+// CandidateReport describes a node that was scored as a possible container
+// of the article content.
+type CandidateReport struct {
+	// Path is the CSS path of the node in the document.
+	Path string `json:"path"`
+	// Score is the final content score, after scaling by link density.
+	Score float64 `json:"score"`
+	// ClassWeight is the weight given by the class name and ID of the node.
+	ClassWeight int `json:"classWeight"`
+	// LinkDensity is the ratio of link text to all text of the node.
+	LinkDensity float64 `json:"linkDensity"`
+}
+
+// RemovedNodeReport describes a node that was removed from the article
+// content, and why.
+type RemovedNodeReport struct {
+	// Path is the CSS path of the node, relative to the article content.
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// startAttemptReport adds a report for the extraction pass that is about to
+// start, using the current flags.
+func (ps *parseState) startAttemptReport() {
+	if ps.diagnostics == nil {
+		return
+	}
+
+	ps.diagnostics.Attempts = append(ps.diagnostics.Attempts, AttemptReport{
+		StripUnlikelys:     ps.flags.stripUnlikelys,
+		UseWeightClasses:   ps.flags.useWeightClasses,
+		CleanConditionally: ps.flags.cleanConditionally,
+	})
+}
+
+// currentAttemptReport returns the report of the running extraction pass,
+// or nil if diagnostics are disabled.
+func (ps *parseState) currentAttemptReport() *AttemptReport {
+	if ps.diagnostics == nil || len(ps.diagnostics.Attempts) == 0 {
+		return nil
+	}
+	return &ps.diagnostics.Attempts[len(ps.diagnostics.Attempts)-1]
+}
+
+// reportCandidates records the top candidates of the running extraction pass.
+func (ps *parseState) reportCandidates(candidates []*html.Node, linkDensities map[*html.Node]float64) {
+	report := ps.currentAttemptReport()
+	if report == nil {
+		return
+	}
+
+	for _, candidate := range candidates {
+		report.Candidates = append(report.Candidates, CandidateReport{
+			Path:        cssPath(candidate),
+			Score:       ps.getContentScore(candidate),
+			ClassWeight: ps.getClassWeight(candidate),
+			LinkDensity: linkDensities[candidate],
+		})
+	}
+}
+
+// explainsRemovals reports whether the reasons of the removals are used,
+// i.e. logged or recorded in the diagnostics, so they're worth building.
+func (ps *parseState) explainsRemovals() bool {
+	return ps.diagnostics != nil || ps.debugEnabled()
+}
+
+// reportRemoval records a node that was removed from the article content.
+func (ps *parseState) reportRemoval(node *html.Node, reason string) {
+	if report := ps.currentAttemptReport(); report != nil {
+		report.Removed = append(report.Removed, RemovedNodeReport{
+			Path:   cssPath(node),
+			Reason: reason,
+		})
+	}
+}
+
+// cssPath returns a CSS selector that identifies node among its ancestors,
+// e.g. "html > body > div#main > p:nth-of-type(2)". Elements are described
+// by their ID, or by their class names if they don't have one.
+func cssPath(node *html.Node) string {
+	var parts []string
+	for n := node; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		part := n.Data
+		if id := dom.ID(n); id != "" {
+			part += "#" + id
+		} else if class := strings.Fields(dom.ClassName(n)); len(class) > 0 && n.Data != "html" && n.Data != "body" {
+			part += "." + strings.Join(class, ".")
+		}
+
+		// Disambiguate from siblings with the same tag name.
+		index, count := 0, 0
+		if n.Parent != nil {
+			for _, sibling := range dom.Children(n.Parent) {
+				if sibling.Data == n.Data {
+					count++
+					if sibling == n {
+						index = count
+					}
+				}
+			}
+		}
+		if count > 1 {
+			part += ":nth-of-type(" + strconv.Itoa(index) + ")"
+		}
+
+		parts = append(parts, part)
+	}
+
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " > ")
+}
@@ -0,0 +1,107 @@
+package readability
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-shiori/dom"
+)
+
+func Test_Diagnostics(t *testing.T) {
+	f, err := os.Open("test-pages/wikipedia/source.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	parser := NewParser()
+	parser.CollectDiagnostics = true
+
+	article, err := parser.Parse(f, fakeHostURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := article.Diagnostics
+	if report == nil {
+		t.Fatal("missing diagnostics")
+	}
+	if len(report.Attempts) == 0 {
+		t.Fatal("no attempts were reported")
+	}
+	if report.SelectedAttempt < 0 || report.SelectedAttempt >= len(report.Attempts) {
+		t.Fatalf("invalid selected attempt: %d", report.SelectedAttempt)
+	}
+
+	selected := report.Attempts[report.SelectedAttempt]
+	if !selected.StripUnlikelys || selected.DroppedFlag != "" {
+		t.Errorf("expected the first attempt to succeed, got %+v", selected)
+	}
+	if selected.TextLength < parser.CharThresholds {
+		t.Errorf("text length of selected attempt is too short: %d", selected.TextLength)
+	}
+	if n := len(selected.Candidates); n == 0 || n > parser.NTopCandidates {
+		t.Errorf("want 1-%d candidates, got %d", parser.NTopCandidates, n)
+	}
+	for i, candidate := range selected.Candidates {
+		if !strings.HasPrefix(candidate.Path, "html > body") {
+			t.Errorf("unexpected candidate path: %q", candidate.Path)
+		}
+		if i > 0 && candidate.Score > selected.Candidates[i-1].Score {
+			t.Errorf("candidates are not sorted by score")
+		}
+	}
+	if len(selected.Removed) == 0 {
+		t.Errorf("expected nodes removed by conditional cleaning")
+	}
+	for _, removed := range selected.Removed {
+		if removed.Reason == "" {
+			t.Errorf("missing reason for removed node %q", removed.Path)
+		}
+	}
+
+	parser.CollectDiagnostics = false
+	article, err = parser.Parse(strings.NewReader("<p>Hello</p>"), fakeHostURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.Diagnostics != nil {
+		t.Error("diagnostics should be nil when disabled")
+	}
+}
+
+func Test_cssPath(t *testing.T) {
+	doc, err := dom.Parse(strings.NewReader(`<div id="main" class="a b"><div class="c d"></div><p>One</p><span></span><p>Two</p></div>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := dom.GetElementsByTagName(doc, "p")[1]
+	if got, want := cssPath(p), "html > body > div#main > p:nth-of-type(2)"; got != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+}
+
+func Test_Diagnostics_removalReason(t *testing.T) {
+	paragraph := "<p>" + strings.Repeat("The council met on Tuesday evening to discuss the new budget, which includes more money for schools. ", 4) + "</p>"
+	gallery := `<div><img src="a.jpg"><img src="b.jpg"><img src="c.jpg"><img src="d.jpg"><img src="e.jpg"><p>Photos</p></div>`
+	html := "<article>" + strings.Repeat(paragraph, 5) + gallery + strings.Repeat(paragraph, 5) + "</article>"
+
+	parser := NewParser()
+	parser.CollectDiagnostics = true
+	article, err := parser.Parse(strings.NewReader(html), fakeHostURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := article.Diagnostics
+	var reasons []string
+	for _, removed := range report.Attempts[report.SelectedAttempt].Removed {
+		reasons = append(reasons, removed.Reason)
+	}
+	want := "bad p to img ratio (img=5, p=2)"
+	if len(reasons) != 1 || reasons[0] != want {
+		t.Errorf("want reason %q got %q", want, reasons)
+	}
+}
@@ -0,0 +1,173 @@
+package readability
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// Byte order marks that are used to detect the encoding of a document.
+var byteOrderMarks = []struct {
+	bom      []byte
+	encoding string
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, "utf-8"},
+	{[]byte{0xFE, 0xFF}, "utf-16be"},
+	{[]byte{0xFF, 0xFE}, "utf-16le"},
+}
+
+// decodeHTML reads the whole input and transcodes it to UTF-8. The encoding
+// is determined by, in order of precedence, the byte order mark, the charset
+// parameter of contentType and the <meta charset> or <meta http-equiv> tag in
+// the document. It returns the decoded content and the canonical name of the
+// detected encoding.
+func decodeHTML(input io.Reader, contentType string) ([]byte, string, error) {
+	content, err := io.ReadAll(input)
+	if err != nil {
+		return nil, "", err
+	}
+
+	enc, name := detectEncoding(content, contentType)
+	if name == "utf-8" {
+		return bytes.TrimPrefix(content, byteOrderMarks[0].bom), name, nil
+	}
+
+	decoded, _, err := transform.Bytes(enc.NewDecoder(), content)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode %s: %v", name, err)
+	}
+	return decoded, name, nil
+}
+
+// detectEncoding determines the encoding of HTML content, as described in
+// https://html.spec.whatwg.org/multipage/parsing.html#determining-the-character-encoding
+//
+// As a go-readability special, an encoding declared in the document itself is
+// ignored when the content is valid UTF-8 with non-ASCII characters, because
+// it's extremely unlikely for legacy encoded text to be valid UTF-8 and pages
+// that were re-saved as UTF-8 often keep their original declaration.
+func detectEncoding(content []byte, contentType string) (encoding.Encoding, string) {
+	for _, b := range byteOrderMarks {
+		if bytes.HasPrefix(content, b.bom) {
+			return charset.Lookup(b.encoding)
+		}
+	}
+
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		if label, ok := params["charset"]; ok {
+			if enc, name := charset.Lookup(label); enc != nil {
+				return enc, name
+			}
+		}
+	}
+
+	isUTF8 := utf8.Valid(content)
+
+	if enc, name := prescanEncoding(content); enc != nil {
+		if !isUTF8 || !hasNonASCII(content) {
+			return enc, name
+		}
+	}
+
+	if isUTF8 {
+		return charset.Lookup("utf-8")
+	}
+
+	return charset.Lookup("windows-1252")
+}
+
+// prescanEncoding looks for the encoding declared by <meta> tags within the
+// first 1024 bytes of content.
+func prescanEncoding(content []byte) (encoding.Encoding, string) {
+	if len(content) > 1024 {
+		content = content[:1024]
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(content))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return nil, ""
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tagName, hasAttr := z.TagName()
+			if !bytes.Equal(tagName, []byte("meta")) {
+				continue
+			}
+
+			var label, httpEquiv, metaContent string
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				switch string(key) {
+				case "charset":
+					label = string(val)
+				case "http-equiv":
+					httpEquiv = strings.ToLower(string(val))
+				case "content":
+					metaContent = string(val)
+				}
+			}
+
+			if label == "" && httpEquiv == "content-type" {
+				label = charsetFromMetaContent(metaContent)
+			}
+
+			if label == "" {
+				continue
+			}
+
+			enc, name := charset.Lookup(label)
+			if enc == nil {
+				continue
+			}
+
+			// A document can't declare itself as UTF-16 since it
+			// wouldn't be able to read the declaration otherwise.
+			if strings.HasPrefix(name, "utf-16") {
+				return charset.Lookup("utf-8")
+			}
+			return enc, name
+		}
+	}
+}
+
+// charsetFromMetaContent extracts the charset from the content attribute of
+// <meta http-equiv="content-type">, e.g. "text/html; charset=shift_jis".
+func charsetFromMetaContent(content string) string {
+	lower := strings.ToLower(content)
+	idx := strings.Index(lower, "charset")
+	if idx < 0 {
+		return ""
+	}
+
+	value := strings.TrimSpace(lower[idx+len("charset"):])
+	if !strings.HasPrefix(value, "=") {
+		return ""
+	}
+
+	value = strings.TrimSpace(value[1:])
+	value = strings.Trim(value, `"'`)
+	if end := strings.IndexAny(value, `;"' `); end >= 0 {
+		value = value[:end]
+	}
+	return value
+}
+
+// hasNonASCII reports whether content contains any byte outside ASCII.
+func hasNonASCII(content []byte) bool {
+	for _, c := range content {
+		if c >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package readability
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func Test_decodeHTML(t *testing.T) {
+	encode := func(enc encoding.Encoding, text string) []byte {
+		b, err := enc.NewEncoder().Bytes([]byte(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name         string
+		input        []byte
+		contentType  string
+		wantEncoding string
+		wantText     string
+	}{
+		{
+			name:         "meta charset",
+			input:        encode(charmap.Windows1251, `<meta charset="windows-1251"><p>Привет, мир</p>`),
+			wantEncoding: "windows-1251",
+			wantText:     "Привет, мир",
+		},
+		{
+			name:         "meta http-equiv",
+			input:        encode(simplifiedchinese.GBK, `<meta http-equiv="Content-Type" content="text/html; charset=gbk"><p>你好世界</p>`),
+			wantEncoding: "gbk",
+			wantText:     "你好世界",
+		},
+		{
+			name:         "content type header",
+			input:        encode(japanese.ShiftJIS, `<p>こんにちは世界</p>`),
+			contentType:  "text/html; charset=Shift_JIS",
+			wantEncoding: "shift_jis",
+			wantText:     "こんにちは世界",
+		},
+		{
+			name:         "content type header takes precedence over meta",
+			input:        encode(japanese.ShiftJIS, `<meta charset="utf-8"><p>こんにちは世界</p>`),
+			contentType:  "text/html; charset=shift_jis",
+			wantEncoding: "shift_jis",
+			wantText:     "こんにちは世界",
+		},
+		{
+			name:         "byte order mark",
+			input:        append([]byte{0xEF, 0xBB, 0xBF}, []byte(`<meta charset="iso-8859-1"><p>Grüße</p>`)...),
+			wantEncoding: "utf-8",
+			wantText:     "Grüße",
+		},
+		{
+			name:         "mislabeled UTF-8",
+			input:        []byte(`<meta charset="gb2312"><p>Grüße</p>`),
+			wantEncoding: "utf-8",
+			wantText:     "Grüße",
+		},
+		{
+			name:         "undeclared legacy encoding",
+			input:        encode(charmap.Windows1252, `<p>Grüße</p>`),
+			wantEncoding: "windows-1252",
+			wantText:     "Grüße",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, name, err := decodeHTML(bytes.NewReader(tt.input), tt.contentType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tt.wantEncoding {
+				t.Errorf("encoding, want %q got %q", tt.wantEncoding, name)
+			}
+			if !bytes.Contains(content, []byte(tt.wantText)) {
+				t.Errorf("content %q doesn't contain %q", content, tt.wantText)
+			}
+		})
+	}
+}
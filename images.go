@@ -0,0 +1,166 @@
+package readability
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+// ArticleImage is an image found in the article content.
+type ArticleImage struct {
+	// URL is the absolute URL of the image, taken from its src, or from the
+	// first srcset candidate if it doesn't have one.
+	URL string `json:"url"`
+	// Srcset lists the candidates of the srcset of the image, including the
+	// ones of the <source> elements when the image is inside a <picture>.
+	Srcset []ImageCandidate `json:"srcset,omitempty"`
+	Alt    string           `json:"alt"`
+	// Caption is the text of the <figcaption> of the figure that contains
+	// the image.
+	Caption string `json:"caption"`
+	// Width and Height are the dimensions declared in the attributes of the
+	// image, or 0 if they are unknown.
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// FromNoscript determines if the image was extracted from a <noscript>,
+	// which is usually the fallback of lazy loaded images.
+	FromNoscript bool `json:"fromNoscript"`
+}
+
+// ImageCandidate is a candidate image of a srcset.
+type ImageCandidate struct {
+	URL string `json:"url"`
+	// Width is the width descriptor of the candidate, e.g. 640 for "640w",
+	// or 0 if the candidate has a density descriptor.
+	Width int `json:"width,omitempty"`
+	// Density is the pixel density descriptor of the candidate, e.g. 2 for
+	// "2x", or 0 if the candidate has a width descriptor.
+	Density float64 `json:"density,omitempty"`
+}
+
+// minLeadImageSize is the minimum declared width and height of an image
+// that can be used as the lead image of the article.
+const minLeadImageSize = 100
+
+// collectImages returns the images in the article content, in the order they
+// appear. Images with the same URL are only listed once.
+func (ps *parseState) collectImages(articleContent *html.Node) []ArticleImage {
+	var images []ArticleImage
+	seen := make(map[string]struct{})
+
+	for _, img := range dom.GetElementsByTagName(articleContent, "img") {
+		candidates := parseSrcset(dom.GetAttribute(img, "srcset"))
+		if picture := img.Parent; picture != nil && dom.TagName(picture) == "picture" {
+			for _, source := range dom.GetElementsByTagName(picture, "source") {
+				candidates = append(candidates, parseSrcset(dom.GetAttribute(source, "srcset"))...)
+			}
+		}
+
+		url := strings.TrimSpace(dom.GetAttribute(img, "src"))
+		if url == "" && len(candidates) > 0 {
+			url = candidates[0].URL
+		}
+		if url == "" {
+			continue
+		}
+
+		if _, exist := seen[url]; exist {
+			continue
+		}
+		seen[url] = struct{}{}
+
+		_, fromNoscript := ps.noscriptImages[url]
+		images = append(images, ArticleImage{
+			URL:          url,
+			Srcset:       candidates,
+			Alt:          normalizeWhitespace(dom.GetAttribute(img, "alt")),
+			Caption:      imageCaption(img, articleContent),
+			Width:        imageDimension(dom.GetAttribute(img, "width")),
+			Height:       imageDimension(dom.GetAttribute(img, "height")),
+			FromNoscript: fromNoscript,
+		})
+	}
+
+	return images
+}
+
+// leadImage returns the URL of the first image that is large enough to
+// represent the article, or an empty string if there is none. Images whose
+// dimensions are not declared are assumed to be large enough.
+func leadImage(images []ArticleImage) string {
+	for _, image := range images {
+		if strings.HasPrefix(image.URL, "data:") {
+			continue
+		}
+		if image.Width > 0 && image.Width < minLeadImageSize {
+			continue
+		}
+		if image.Height > 0 && image.Height < minLeadImageSize {
+			continue
+		}
+		return image.URL
+	}
+	return ""
+}
+
+// parseSrcset parses the candidates of a srcset attribute.
+func parseSrcset(srcset string) []ImageCandidate {
+	var candidates []ImageCandidate
+	for _, match := range rxSrcsetURL.FindAllStringSubmatch(srcset, -1) {
+		url := strings.TrimSuffix(match[1], ",")
+		if url == "" {
+			continue
+		}
+
+		candidate := ImageCandidate{URL: url, Density: 1}
+		if descriptor := strings.TrimSpace(match[2]); descriptor != "" {
+			value := descriptor[:len(descriptor)-1]
+			switch descriptor[len(descriptor)-1] {
+			case 'w':
+				candidate.Density = 0
+				candidate.Width, _ = strconv.Atoi(value)
+			case 'x':
+				candidate.Density, _ = strconv.ParseFloat(value, 64)
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// imageCaption returns the text of the caption of the figure that contains
+// img, looking no further than root.
+func imageCaption(img, root *html.Node) string {
+	for node := img.Parent; node != nil && node != root.Parent; node = node.Parent {
+		if dom.TagName(node) != "figure" {
+			continue
+		}
+
+		if captions := dom.GetElementsByTagName(node, "figcaption"); len(captions) > 0 {
+			return normalizeWhitespace(dom.TextContent(captions[0]))
+		}
+		return ""
+	}
+	return ""
+}
+
+// imageDimension parses the value of a width or height attribute, which may
+// have a "px" suffix. It returns 0 if the value is not a number of pixels.
+func imageDimension(value string) int {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "px")
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return int(n)
+}
+
+// recordNoscriptImage remembers the URL of an image that was extracted from
+// a <noscript>, so it can be flagged in the image inventory.
+func (ps *parseState) recordNoscriptImage(img *html.Node) {
+	if src := strings.TrimSpace(dom.GetAttribute(img, "src")); src != "" {
+		ps.noscriptImages[toAbsoluteURI(src, ps.documentURI)] = struct{}{}
+	}
+}
@@ -0,0 +1,62 @@
+package readability
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const imagesTestPage = `<html><head><title>Images</title></head><body><article>
+<p>This is the first paragraph of a story about images, it has to be long enough to be picked up as content.</p>
+<img src="/icons/share.png" width="16" height="16" alt="share">
+<figure>
+<img src="/photos/lazy-placeholder.gif" alt="placeholder">
+<noscript><img src="/photos/beach.jpg" alt="A beach" width="800px" height="600"></noscript>
+<figcaption>The  beach at sunset</figcaption>
+</figure>
+<picture><source srcset="/photos/tree.webp 1x, /photos/tree@2x.webp 2x"><img src="/photos/tree.jpg" srcset="/photos/tree-640.jpg 640w" alt="A tree"></picture>
+<p>This is the second paragraph of the story about images, and it's also long enough to be picked up as content.</p>
+</article></body></html>`
+
+func Test_collectImages(t *testing.T) {
+	parser := NewParser()
+	article, err := parser.Parse(strings.NewReader(imagesTestPage), fakeHostURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ArticleImage{
+		{
+			URL:    "http://fakehost/icons/share.png",
+			Alt:    "share",
+			Width:  16,
+			Height: 16,
+		},
+		{
+			URL:          "http://fakehost/photos/beach.jpg",
+			Alt:          "A beach",
+			Caption:      "The beach at sunset",
+			Width:        800,
+			Height:       600,
+			FromNoscript: true,
+		},
+		{
+			URL: "http://fakehost/photos/tree.jpg",
+			Srcset: []ImageCandidate{
+				{URL: "http://fakehost/photos/tree-640.jpg", Width: 640},
+				{URL: "http://fakehost/photos/tree.webp", Density: 1},
+				{URL: "http://fakehost/photos/tree@2x.webp", Density: 2},
+			},
+			Alt: "A tree",
+		},
+	}
+
+	if !reflect.DeepEqual(article.Images, want) {
+		t.Errorf("images\nwant: %+v\ngot : %+v", want, article.Images)
+	}
+
+	// The share icon is too small to be the lead image.
+	if article.Image != "http://fakehost/photos/beach.jpg" {
+		t.Errorf("lead image, want %q got %q", "http://fakehost/photos/beach.jpg", article.Image)
+	}
+}
@@ -0,0 +1,268 @@
+// Package langdetect guesses the language of a text without any network
+// access. Languages written in their own script are recognized by it, while
+// the languages written in the Latin or Cyrillic script are told apart by
+// comparing the character trigrams of the text with the profiles of the
+// sample texts bundled in the package, as described by Cavnar and Trenkle
+// in "N-Gram-Based Text Categorization".
+package langdetect
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed samples/*.txt
+var samples embed.FS
+
+const (
+	// profileSize is the number of most frequent trigrams kept in a profile.
+	profileSize = 300
+	// maxRunes is the number of runes of a text that are looked at, which
+	// is more than enough to recognize its language.
+	maxRunes = 10000
+	// minLetters is the number of letters a text needs for its language to
+	// be guessed.
+	minLetters = 20
+	// minTrigramLetters is the number of letters a text in the Latin or
+	// Cyrillic script needs for its language to be guessed from its
+	// trigrams, which are too few to compare in shorter texts.
+	minTrigramLetters = 50
+	// reliableLetters is the number of letters from which a guess based on
+	// the trigrams gets its full confidence. The profiles are built from
+	// small samples, so the guesses for shorter texts are less reliable.
+	reliableLetters = 1000
+	// minConfidence is the confidence under which the closest profile is
+	// too close to the others to be trusted, which is the case for the
+	// languages that don't have a profile, e.g. Latin.
+	minConfidence = 0.05
+)
+
+// profile is the rank of the most frequent trigrams of a language.
+type profile struct {
+	lang   string
+	script *unicode.RangeTable
+	ranks  map[string]int
+}
+
+var (
+	profilesOnce sync.Once
+	profiles     []profile
+)
+
+// scriptLanguages are the scripts that are only used by a single language,
+// for the purpose of this package.
+var scriptLanguages = []struct {
+	script *unicode.RangeTable
+	lang   string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Thai, "th"},
+	{unicode.Greek, "el"},
+	{unicode.Hebrew, "he"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Arabic, "ar"},
+	{unicode.Armenian, "hy"},
+	{unicode.Georgian, "ka"},
+}
+
+// Detect returns the ISO 639-1 code of the language of text, and the
+// confidence of the guess between 0 and 1. It returns an empty language if
+// text is too short or its language isn't recognized. The confidence of the
+// guesses based on trigrams is lowered for texts shorter than
+// reliableLetters.
+func Detect(text string) (string, float64) {
+	if runes := []rune(text); len(runes) > maxRunes {
+		text = string(runes[:maxRunes])
+	}
+
+	// Count the letters of each script
+	var letters, latin, cyrillic, han, kana int
+	scripts := make([]int, len(scriptLanguages))
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		default:
+			for i, sl := range scriptLanguages {
+				if unicode.Is(sl.script, r) {
+					scripts[i]++
+					break
+				}
+			}
+		}
+	}
+
+	if letters < minLetters {
+		return "", 0
+	}
+
+	// Japanese is written with both kanji and kana, while Chinese only
+	// uses the Han characters
+	lang, count := "", 0
+	switch {
+	case kana > 0 && han+kana > count:
+		lang, count = "ja", han+kana
+	case han > count:
+		lang, count = "zh", han
+	}
+	for i, sl := range scriptLanguages {
+		if scripts[i] > count {
+			lang, count = sl.lang, scripts[i]
+		}
+	}
+
+	if latin <= count && cyrillic <= count {
+		return lang, float64(count) / float64(letters)
+	}
+
+	if letters < minTrigramLetters {
+		return "", 0
+	}
+
+	script := unicode.Latin
+	if cyrillic > latin {
+		script = unicode.Cyrillic
+	}
+	lang, confidence := detectTrigrams(text, script)
+	if letters < reliableLetters {
+		confidence *= float64(letters) / reliableLetters
+	}
+	return lang, confidence
+}
+
+// detectTrigrams guesses the language of text among the profiles of the
+// languages written in script. The confidence is the relative difference
+// between the distance to the closest profile and the distance to the
+// second closest one.
+func detectTrigrams(text string, script *unicode.RangeTable) (string, float64) {
+	profilesOnce.Do(loadProfiles)
+
+	ranks := rankTrigrams(text)
+	if len(ranks) == 0 {
+		return "", 0
+	}
+
+	best, second := "", -1
+	bestDistance := -1
+	for _, p := range profiles {
+		if p.script != script {
+			continue
+		}
+
+		distance := 0
+		for trigram, rank := range ranks {
+			if profileRank, ok := p.ranks[trigram]; ok {
+				distance += abs(rank - profileRank)
+			} else {
+				distance += profileSize
+			}
+		}
+
+		switch {
+		case bestDistance < 0 || distance < bestDistance:
+			second = bestDistance
+			best, bestDistance = p.lang, distance
+		case second < 0 || distance < second:
+			second = distance
+		}
+	}
+
+	if best == "" || second <= 0 {
+		return "", 0
+	}
+
+	confidence := float64(second-bestDistance) / float64(second)
+	if confidence < minConfidence {
+		return "", 0
+	}
+	return best, confidence
+}
+
+// loadProfiles builds the profiles of the sample texts.
+func loadProfiles() {
+	entries, err := samples.ReadDir("samples")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, entry := range entries {
+		content, err := samples.ReadFile(path.Join("samples", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		text := string(content)
+		script := unicode.Latin
+		for _, r := range text {
+			if unicode.Is(unicode.Cyrillic, r) {
+				script = unicode.Cyrillic
+				break
+			}
+		}
+
+		profiles = append(profiles, profile{
+			lang:   strings.TrimSuffix(entry.Name(), ".txt"),
+			script: script,
+			ranks:  rankTrigrams(text),
+		})
+	}
+}
+
+// rankTrigrams returns the rank of the profileSize most frequent trigrams
+// of the words of text. Each word is padded with a space on both sides, so
+// its first and last letters have trigrams of their own.
+func rankTrigrams(text string) map[string]int {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			counts[string(runes[i:i+3])]++
+		}
+	}
+
+	trigrams := make([]string, 0, len(counts))
+	for trigram := range counts {
+		trigrams = append(trigrams, trigram)
+	}
+	sort.Slice(trigrams, func(i, j int) bool {
+		a, b := trigrams[i], trigrams[j]
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		return a < b
+	})
+	if len(trigrams) > profileSize {
+		trigrams = trigrams[:profileSize]
+	}
+
+	ranks := make(map[string]int, len(trigrams))
+	for rank, trigram := range trigrams {
+		ranks[trigram] = rank
+	}
+	return ranks
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
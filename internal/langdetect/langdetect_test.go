@@ -0,0 +1,65 @@
+package langdetect
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_Detect(t *testing.T) {
+	scenarios := []struct {
+		text string
+		want string
+	}{
+		{"The quick brown fox jumps over the lazy dog while the farmer watches from his porch.", "en"},
+		{"Le petit chat dort sur le canapé pendant que les enfants jouent dans le jardin.", "fr"},
+		{"El perro de mis vecinos ladra todas las noches cuando pasa el tren por la estación.", "es"},
+		{"Die Kinder spielen im Garten, während die Katze auf dem Sofa schläft und träumt.", "de"},
+		{"I bambini giocano nel giardino mentre il gatto dorme sul divano della nonna.", "it"},
+		{"As crianças brincam no jardim enquanto o gato dorme no sofá da avó.", "pt"},
+		{"De kinderen spelen in de tuin terwijl de kat op de bank ligt te slapen. Morgen gaan we met de trein naar zee, als het weer mooi blijft.", "nl"},
+		{"Barnen leker i trädgården medan katten sover på soffan hos mormor. I morgon tar vi tåget till havet, om vädret fortsätter att vara fint.", "sv"},
+		{"Lapset leikkivät puutarhassa, kun kissa nukkuu sohvalla isoäidin luona.", "fi"},
+		{"Dzieci bawią się w ogrodzie, podczas gdy kot śpi na kanapie u babci.", "pl"},
+		{"Copiii se joacă în grădină în timp ce pisica doarme pe canapeaua bunicii.", "ro"},
+		{"Çocuklar bahçede oynarken kedi büyükannenin kanepesinde uyuyor.", "tr"},
+		{"Вчера вечером мы долго гуляли по парку и разговаривали о погоде и о новой книге. Завтра мы поедем на поезде к морю, если погода будет хорошей.", "ru"},
+		{"Вчора ввечері ми довго гуляли парком і розмовляли про погоду та про нову книжку.", "uk"},
+		{"子どもたちは庭で遊んでいて、猫はおばあさんのソファで寝ています。", "ja"},
+		{"孩子们在花园里玩耍，猫在奶奶家的沙发上睡觉。", "zh"},
+		{"아이들은 정원에서 놀고 고양이는 할머니 집 소파에서 자고 있습니다.", "ko"},
+		{"Τα παιδιά παίζουν στον κήπο ενώ η γάτα κοιμάται στον καναπέ.", "el"},
+		{"Lorem ipsum dolor sit amet, consectetur adipisicing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.", ""},
+		{"Too short.", ""},
+		{"1234 5678 ... !!!", ""},
+	}
+
+	for _, scenario := range scenarios {
+		lang, confidence := Detect(scenario.text)
+		if lang != scenario.want {
+			t.Errorf("Detect(%q): want %q got %q", scenario.text, scenario.want, lang)
+		}
+		if confidence < 0 || confidence > 1 || (lang != "") != (confidence > 0) {
+			t.Errorf("Detect(%q): unexpected confidence %v", scenario.text, confidence)
+		}
+	}
+}
+
+func Test_Detect_confidence(t *testing.T) {
+	const sentence = "The quick brown fox jumps over the lazy dog while the farmer watches from his porch. "
+
+	if lang, _ := Detect("The fox jumps over the lazy dog."); lang != "" {
+		t.Errorf("short text: want no language got %q", lang)
+	}
+
+	lang, short := Detect(sentence)
+	if lang != "en" {
+		t.Fatalf("sentence: want %q got %q", "en", lang)
+	}
+	lang, long := Detect(strings.Repeat(sentence, 20))
+	if lang != "en" {
+		t.Fatalf("repeated sentence: want %q got %q", "en", lang)
+	}
+	if short >= long {
+		t.Errorf("want a lower confidence for the sentence than for its repetition, got %v and %v", short, long)
+	}
+}
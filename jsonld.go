@@ -0,0 +1,435 @@
+package readability
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+// LinkedData is the Schema.org metadata of the article, found in the JSON-LD
+// of the page.
+type LinkedData struct {
+	// ID is the @id of the article node.
+	ID string `json:"id,omitempty"`
+	// Types are the Schema.org types of the article, e.g. "NewsArticle".
+	Types       []string `json:"types"`
+	Name        string   `json:"name,omitempty"`
+	Headline    string   `json:"headline,omitempty"`
+	Description string   `json:"description,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	// MainEntityOfPage is the URL of the page the article is the main
+	// entity of.
+	MainEntityOfPage string   `json:"mainEntityOfPage,omitempty"`
+	Images           []string `json:"images,omitempty"`
+	Keywords         []string `json:"keywords,omitempty"`
+	// Sections are the values of articleSection, e.g. "Sports".
+	Sections []string `json:"sections,omitempty"`
+	// Language is the value of inLanguage, e.g. "en-US".
+	Language  string `json:"language,omitempty"`
+	WordCount int    `json:"wordCount,omitempty"`
+	// IsAccessibleForFree is nil when the page doesn't tell whether the
+	// article is behind a paywall.
+	IsAccessibleForFree *bool              `json:"isAccessibleForFree,omitempty"`
+	DatePublished       string             `json:"datePublished,omitempty"`
+	DateModified        string             `json:"dateModified,omitempty"`
+	Authors             []LinkedDataEntity `json:"authors,omitempty"`
+	Publisher           *LinkedDataEntity  `json:"publisher,omitempty"`
+}
+
+// LinkedDataEntity is a person or an organization in LinkedData.
+type LinkedDataEntity struct {
+	// Type is the Schema.org type of the entity, usually "Person" or
+	// "Organization". It's empty if the entity is only a name.
+	Type string `json:"type,omitempty"`
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+	// Logo is the URL of the logo of an organization.
+	Logo string `json:"logo,omitempty"`
+	// Affiliation is the name of the organization a person is affiliated
+	// with.
+	Affiliation string `json:"affiliation,omitempty"`
+}
+
+// jsonLDGraph is the set of Schema.org nodes of every JSON-LD script in the
+// page. Nodes with the same @id are merged, so references to them can be
+// resolved.
+type jsonLDGraph struct {
+	nodes []map[string]interface{}
+	byID  map[string]map[string]interface{}
+}
+
+// add collects the nodes in value, and in any value nested in it, including
+// @graph lists. The @context is inherited from the enclosing objects.
+func (g *jsonLDGraph) add(value interface{}, schemaOrg bool) {
+	switch val := value.(type) {
+	case []interface{}:
+		for _, item := range val {
+			g.add(item, schemaOrg)
+		}
+
+	case map[string]interface{}:
+		if ctx, exist := val["@context"]; exist {
+			schemaOrg = isSchemaOrgContext(ctx)
+		}
+
+		if schemaOrg {
+			g.addNode(val)
+		}
+
+		// Nested objects are visited in a fixed order, so the nodes are
+		// always found in the same order.
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			if key != "@context" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			g.add(val[key], schemaOrg)
+		}
+	}
+}
+
+// addNode adds a single object to the graph, or merges it with the node
+// that has the same @id.
+func (g *jsonLDGraph) addNode(node map[string]interface{}) {
+	// An object that only has an @id is a reference, which is resolved
+	// later.
+	id, _ := node["@id"].(string)
+	if id != "" && len(node) == 1 {
+		return
+	}
+
+	if existing, exist := g.byID[id]; exist && id != "" {
+		_, wasTyped := existing["@type"]
+		for key, item := range node {
+			if _, exist := existing[key]; !exist {
+				existing[key] = item
+			}
+		}
+
+		// The node is only listed once it has a type, which may be
+		// declared after its other properties, e.g. in another script.
+		if _, typed := existing["@type"]; typed && !wasTyped {
+			g.nodes = append(g.nodes, existing)
+		}
+		return
+	}
+
+	if id != "" {
+		g.byID[id] = node
+	}
+	if _, typed := node["@type"]; typed {
+		g.nodes = append(g.nodes, node)
+	}
+}
+
+// resolve returns the node that value refers to with its @id, or value
+// itself if it's not a reference to a known node.
+func (g *jsonLDGraph) resolve(value interface{}) interface{} {
+	obj, isObj := value.(map[string]interface{})
+	if !isObj || len(obj) != 1 {
+		return value
+	}
+
+	if id, isString := obj["@id"].(string); isString {
+		if node, exist := g.byID[id]; exist {
+			return node
+		}
+	}
+	return value
+}
+
+// article returns the first node whose type is Article or one of its
+// subtypes.
+func (g *jsonLDGraph) article() map[string]interface{} {
+	for _, node := range g.nodes {
+		for _, nodeType := range jsonLDStrings(node["@type"]) {
+			if rxJsonLdArticleTypes.MatchString(nodeType) {
+				return node
+			}
+		}
+	}
+	return nil
+}
+
+// getJSONLD try to extract metadata from JSON-LD object.
+// For now, only Schema.org objects of type Article or its subtypes are
+// supported. The objects of every JSON-LD script are merged, so the
+// article may be described by several scripts or @graph entries.
+func (ps *parseState) getJSONLD() (map[string]string, *LinkedData) {
+	graph := &jsonLDGraph{byID: make(map[string]map[string]interface{})}
+
+	scripts := dom.QuerySelectorAll(ps.doc, `script[type="application/ld+json"]`)
+	ps.forEachNode(scripts, func(jsonLdElement *html.Node, _ int) {
+		// Strip CDATA markers if present
+		content := rxCDATA.ReplaceAllString(dom.TextContent(jsonLdElement), "")
+
+		// Decode JSON
+		var parsedContent interface{}
+		err := json.Unmarshal([]byte(content), &parsedContent)
+		if err != nil {
+			ps.logf("error while decoding json: %v", err)
+			return
+		}
+
+		graph.add(parsedContent, false)
+	})
+
+	parsed := graph.article()
+	if parsed == nil {
+		if len(scripts) > 0 {
+			ps.log("unrecognized JSON-LD structure")
+		}
+		return nil, nil
+	}
+
+	data := graph.linkedData(parsed)
+
+	// Initiate metadata
+	metadata := make(map[string]string)
+
+	// Title
+	_, nameIsString := parsed["name"].(string)
+	_, headlineIsString := parsed["headline"].(string)
+
+	if nameIsString && headlineIsString && data.Name != data.Headline {
+		// We have both name and headline element in the JSON-LD. They should both be the same
+		// but some websites like aktualne.cz put their own name into "name" and the article
+		// title to "headline" which confuses Readability. So we try to check if either "name"
+		// or "headline" closely matches the html title, and if so, use that one. If not, then
+		// we use "name" by default.
+		title := ps.getArticleTitle()
+		nameMatches := ps.textSimilarity(data.Name, title) > 0.75
+		headlineMatches := ps.textSimilarity(data.Headline, title) > 0.75
+
+		if headlineMatches && !nameMatches {
+			metadata["title"] = data.Headline
+		} else {
+			metadata["title"] = data.Name
+		}
+	} else if nameIsString {
+		metadata["title"] = strings.TrimSpace(data.Name)
+	} else if headlineIsString {
+		metadata["title"] = strings.TrimSpace(data.Headline)
+	}
+
+	// Author. Like in Readability.js, the authors that are only a name
+	// are ignored.
+	var authors []string
+	for _, author := range jsonLDList(parsed["author"]) {
+		if _, isObj := graph.resolve(author).(map[string]interface{}); !isObj {
+			continue
+		}
+		if entity := graph.entity(author); entity != nil {
+			authors = append(authors, entity.Name)
+		}
+	}
+	if len(authors) > 0 {
+		metadata["byline"] = strings.Join(authors, ", ")
+	}
+
+	// Description
+	if _, isString := parsed["description"].(string); isString {
+		metadata["excerpt"] = data.Description
+	}
+
+	// Publisher
+	if data.Publisher != nil && data.Publisher.Name != "" {
+		metadata["siteName"] = data.Publisher.Name
+	}
+
+	// Image
+	if len(data.Images) > 0 {
+		metadata["image"] = data.Images[0]
+	}
+
+	// DatePublished and DateModified
+	if data.DatePublished != "" {
+		metadata["datePublished"] = data.DatePublished
+	}
+	if data.DateModified != "" {
+		metadata["dateModified"] = data.DateModified
+	}
+
+	return metadata, data
+}
+
+// linkedData converts the article node of the graph to LinkedData.
+func (g *jsonLDGraph) linkedData(node map[string]interface{}) *LinkedData {
+	data := &LinkedData{
+		Types:            jsonLDStrings(node["@type"]),
+		Name:             jsonLDText(node["name"]),
+		Headline:         jsonLDText(node["headline"]),
+		Description:      strings.TrimSpace(jsonLDText(node["description"])),
+		URL:              jsonLDText(node["url"]),
+		MainEntityOfPage: jsonLDURL(g.resolve(node["mainEntityOfPage"])),
+		Sections:         jsonLDStrings(node["articleSection"]),
+		Language:         jsonLDText(node["inLanguage"]),
+		DatePublished:    jsonLDText(node["datePublished"]),
+		DateModified:     jsonLDText(node["dateModified"]),
+	}
+	data.ID, _ = node["@id"].(string)
+
+	// The language may also be an object, e.g. {"@type": "Language", "name": "English"}
+	if lang, isObj := g.resolve(node["inLanguage"]).(map[string]interface{}); isObj {
+		data.Language = strOr(jsonLDText(lang["alternateName"]), jsonLDText(lang["name"]))
+	}
+
+	for _, image := range jsonLDList(node["image"]) {
+		if url := jsonLDURL(g.resolve(image)); url != "" {
+			data.Images = append(data.Images, url)
+		}
+	}
+
+	// Keywords are either a list or a single comma separated text
+	for _, keyword := range jsonLDList(node["keywords"]) {
+		switch val := g.resolve(keyword).(type) {
+		case string:
+			for _, word := range strings.Split(val, ",") {
+				if word = strings.TrimSpace(word); word != "" {
+					data.Keywords = append(data.Keywords, word)
+				}
+			}
+		case map[string]interface{}:
+			if name := jsonLDText(val["name"]); name != "" {
+				data.Keywords = append(data.Keywords, name)
+			}
+		}
+	}
+
+	switch val := node["wordCount"].(type) {
+	case float64:
+		data.WordCount = int(val)
+	case string:
+		data.WordCount, _ = strconv.Atoi(strings.TrimSpace(val))
+	}
+
+	switch val := node["isAccessibleForFree"].(type) {
+	case bool:
+		data.IsAccessibleForFree = &val
+	case string:
+		if free, err := strconv.ParseBool(strings.TrimSpace(val)); err == nil {
+			data.IsAccessibleForFree = &free
+		}
+	}
+
+	for _, author := range jsonLDList(node["author"]) {
+		if entity := g.entity(author); entity != nil {
+			data.Authors = append(data.Authors, *entity)
+		}
+	}
+
+	if publishers := jsonLDList(node["publisher"]); len(publishers) > 0 {
+		data.Publisher = g.entity(publishers[0])
+	}
+
+	return data
+}
+
+// entity converts a person or an organization to LinkedDataEntity. It
+// returns nil if value doesn't have a name.
+func (g *jsonLDGraph) entity(value interface{}) *LinkedDataEntity {
+	obj, isObj := g.resolve(value).(map[string]interface{})
+	if !isObj {
+		// The entity may be only a name, but not a dangling reference
+		if name, isString := value.(string); isString && strings.TrimSpace(name) != "" {
+			return &LinkedDataEntity{Name: strings.TrimSpace(name)}
+		}
+		return nil
+	}
+
+	name, isString := obj["name"].(string)
+	if !isString {
+		return nil
+	}
+
+	entity := &LinkedDataEntity{
+		Name: strings.TrimSpace(name),
+		URL:  jsonLDText(obj["url"]),
+		Logo: jsonLDURL(g.resolve(obj["logo"])),
+	}
+	if types := jsonLDStrings(obj["@type"]); len(types) > 0 {
+		entity.Type = types[0]
+	}
+
+	if affiliations := jsonLDList(obj["affiliation"]); len(affiliations) > 0 {
+		switch val := g.resolve(affiliations[0]).(type) {
+		case string:
+			entity.Affiliation = strings.TrimSpace(val)
+		case map[string]interface{}:
+			entity.Affiliation = strings.TrimSpace(jsonLDText(val["name"]))
+		}
+	}
+
+	return entity
+}
+
+// isSchemaOrgContext determines if the @context of a JSON-LD object is
+// Schema.org.
+func isSchemaOrgContext(ctx interface{}) bool {
+	switch ct := ctx.(type) {
+	case string:
+		return rxSchemaOrg.MatchString(ct)
+	case map[string]interface{}:
+		vocabStr, ok := ct["@vocab"].(string)
+		return ok && rxSchemaOrg.MatchString(vocabStr)
+	}
+	return false
+}
+
+// jsonLDList returns value as a list, since most properties can have either
+// a single value or several of them.
+func jsonLDList(value interface{}) []interface{} {
+	switch val := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return val
+	}
+	return []interface{}{value}
+}
+
+// jsonLDText returns value if it's a string, or the first string of value
+// if it's a list.
+func jsonLDText(value interface{}) string {
+	strs := jsonLDStrings(value)
+	if len(strs) == 0 {
+		return ""
+	}
+	return strs[0]
+}
+
+// jsonLDStrings returns the strings of value, which is either a string or a
+// list of strings.
+func jsonLDStrings(value interface{}) []string {
+	var strs []string
+	for _, item := range jsonLDList(value) {
+		if str, isString := item.(string); isString && strings.TrimSpace(str) != "" {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
+
+// jsonLDURL returns the URL of value, which is either the URL itself or an
+// object like ImageObject or WebPage.
+func jsonLDURL(value interface{}) string {
+	switch val := value.(type) {
+	case string:
+		return strings.TrimSpace(val)
+	case []interface{}:
+		if len(val) > 0 {
+			return jsonLDURL(val[0])
+		}
+	case map[string]interface{}:
+		return strOr(jsonLDText(val["url"]), jsonLDText(val["contentUrl"]), jsonLDText(val["@id"]))
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+package readability
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_getJSONLD(t *testing.T) {
+	page := `<html><head><title>Local Council Approves the New Library</title>
+<script type="application/ld+json">{
+	"@context": "https://schema.org",
+	"@graph": [
+		{"@type": "WebPage", "@id": "https://example.com/library#webpage", "url": "https://example.com/library"},
+		{
+			"@type": ["NewsArticle", "Article"],
+			"@id": "https://example.com/library#article",
+			"headline": "Local Council Approves the New Library",
+			"mainEntityOfPage": {"@id": "https://example.com/library#webpage"},
+			"author": [{"@id": "https://example.com/#jane"}, "Desk"],
+			"publisher": {"@id": "https://example.com/#org"},
+			"image": {"@id": "https://example.com/library#image"},
+			"keywords": "council, library,  budget",
+			"articleSection": ["Local", "Politics"],
+			"inLanguage": "en-GB",
+			"wordCount": "812",
+			"isAccessibleForFree": "False"
+		},
+		{"@type": "ImageObject", "@id": "https://example.com/library#image", "url": "https://example.com/library.jpg"}
+	]
+}</script>
+<script type="application/ld+json">[
+	{
+		"@context": "http://schema.org",
+		"@type": "Person",
+		"@id": "https://example.com/#jane",
+		"name": "Jane Doe",
+		"url": "https://example.com/authors/jane",
+		"affiliation": {"@type": "Organization", "name": "Example Gazette"}
+	},
+	{
+		"@context": "http://schema.org",
+		"@type": "Organization",
+		"@id": "https://example.com/#org",
+		"name": "Example News",
+		"logo": {"@type": "ImageObject", "url": "https://example.com/logo.png"}
+	},
+	{
+		"@context": "http://schema.org",
+		"@id": "https://example.com/library#article",
+		"datePublished": "2024-05-17T08:00:00Z",
+		"dateModified": "2024-05-18T09:30:00Z"
+	}
+]</script>
+</head><body><article><p>The council voted on Tuesday to fund the new library.</p></article></body></html>`
+
+	parser := NewParser()
+	article, err := parser.Parse(strings.NewReader(page), fakeHostURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	free := false
+	want := &LinkedData{
+		ID:                  "https://example.com/library#article",
+		Types:               []string{"NewsArticle", "Article"},
+		Headline:            "Local Council Approves the New Library",
+		MainEntityOfPage:    "https://example.com/library",
+		Images:              []string{"https://example.com/library.jpg"},
+		Keywords:            []string{"council", "library", "budget"},
+		Sections:            []string{"Local", "Politics"},
+		Language:            "en-GB",
+		WordCount:           812,
+		IsAccessibleForFree: &free,
+		DatePublished:       "2024-05-17T08:00:00Z",
+		DateModified:        "2024-05-18T09:30:00Z",
+		Authors: []LinkedDataEntity{
+			{Type: "Person", Name: "Jane Doe", URL: "https://example.com/authors/jane", Affiliation: "Example Gazette"},
+			{Name: "Desk"},
+		},
+		Publisher: &LinkedDataEntity{Type: "Organization", Name: "Example News", Logo: "https://example.com/logo.png"},
+	}
+
+	if !reflect.DeepEqual(article.LinkedData, want) {
+		t.Errorf("linked data\nwant: %+v\ngot : %+v", want, article.LinkedData)
+	}
+
+	if want := "Jane Doe"; article.Byline != want {
+		t.Errorf("byline, want %q got %q", want, article.Byline)
+	}
+	if want := "Example News"; article.SiteName != want {
+		t.Errorf("site name, want %q got %q", want, article.SiteName)
+	}
+	if want := "https://example.com/library.jpg"; article.Image != want {
+		t.Errorf("image, want %q got %q", want, article.Image)
+	}
+
+	modified := time.Date(2024, 5, 18, 9, 30, 0, 0, time.UTC)
+	if article.ModifiedTime == nil || !article.ModifiedTime.Equal(modified) {
+		t.Errorf("modified time, want %v got %v", modified, article.ModifiedTime)
+	}
+
+	// The properties of the article may come before its type, e.g. when
+	// the script without a type is the first one.
+	datesOnly := `<script type="application/ld+json">{"@context": "https://schema.org", "@id": "https://example.com/library#article",
+	"datePublished": "2024-05-17T08:00:00Z"}</script>`
+	typed := `<script type="application/ld+json">{"@context": "https://schema.org", "@type": "NewsArticle",
+	"@id": "https://example.com/library#article", "headline": "Local Council Approves the New Library"}</script>`
+	page = `<html><head>` + datesOnly + typed + `</head><body><article><p>The council voted on Tuesday to fund the new library.</p></article></body></html>`
+
+	article, err = parser.Parse(strings.NewReader(page), fakeHostURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want = &LinkedData{
+		ID:            "https://example.com/library#article",
+		Types:         []string{"NewsArticle"},
+		Headline:      "Local Council Approves the New Library",
+		DatePublished: "2024-05-17T08:00:00Z",
+	}
+	if !reflect.DeepEqual(article.LinkedData, want) {
+		t.Errorf("linked data of typeless script first\nwant: %+v\ngot : %+v", want, article.LinkedData)
+	}
+}
+
+func Test_getJSONLD_notSchemaOrg(t *testing.T) {
+	page := `<html><head>
+<script type="application/ld+json">{"@context": "https://example.org", "@type": "Article", "headline": "Not Schema.org"}</script>
+</head><body><p>Text</p></body></html>`
+
+	parser := NewParser()
+	article, err := parser.Parse(strings.NewReader(page), fakeHostURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if article.LinkedData != nil {
+		t.Errorf("want no linked data got %+v", article.LinkedData)
+	}
+}
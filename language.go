@@ -0,0 +1,57 @@
+package readability
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-shiori/go-readability/internal/langdetect"
+)
+
+var rxLanguageTag = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{1,8})*$`)
+
+// resolveLanguage returns the language of the article and the confidence
+// that it's right. The lang attribute of the <html> element comes first,
+// then the languages declared in <meta> tags, the Content-Language header
+// and JSON-LD, all normalized by languageTag. If the page doesn't declare
+// its language, it's guessed from text, unless the detection is disabled.
+func (ps *parseState) resolveLanguage(linkedData *LinkedData, text string) (string, float64) {
+	var jsonLdLanguage string
+	if linkedData != nil {
+		jsonLdLanguage = linkedData.Language
+	}
+
+	lang := ps.pickMetadata("language", []metadataSource{
+		{"<html>", languageTag(ps.articleLang)},
+		{"content-language", languageTag(ps.rawMetadata["content-language"])},
+		{"header", languageTag(ps.ContentLanguage)},
+		{"og:locale", languageTag(ps.rawMetadata["og:locale"])},
+		{"jsonld", languageTag(jsonLdLanguage)},
+	})
+	if lang != "" {
+		return lang, 1
+	}
+
+	if ps.DisableLanguageDetection {
+		return "", 0
+	}
+
+	lang, confidence := langdetect.Detect(text)
+	if lang != "" {
+		ps.metadataSources["language"] = "detected"
+		ps.logf("detected language %q with confidence %.2f\n", lang, confidence)
+	}
+	return lang, confidence
+}
+
+// languageTag returns the first language of a declaration like the value
+// of the Content-Language header, e.g. "en-US" for "en-US, fr". Locales
+// like "en_US" are converted to language tags. It returns an empty string
+// if the declaration isn't a language tag, e.g. "English".
+func languageTag(declaration string) string {
+	tag, _, _ := strings.Cut(declaration, ",")
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if !rxLanguageTag.MatchString(tag) {
+		return ""
+	}
+	return tag
+}
@@ -0,0 +1,118 @@
+package readability
+
+import (
+	nurl "net/url"
+	"strings"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+	"golang.org/x/net/publicsuffix"
+)
+
+// LinkScope describes where a link points to, relative to the page.
+type LinkScope string
+
+const (
+	// LinkInternal is a link to the same host as the page, including the
+	// in-page references.
+	LinkInternal LinkScope = "internal"
+	// LinkSameSite is a link to another host of the same registrable
+	// domain, e.g. from www.example.com to blog.example.com.
+	LinkSameSite LinkScope = "same-site"
+	// LinkExternal is a link to another site.
+	LinkExternal LinkScope = "external"
+	// LinkOther is a link that doesn't point to a web page, e.g. "mailto:".
+	LinkOther LinkScope = "other"
+)
+
+// ArticleLink is a link found in the article content.
+type ArticleLink struct {
+	// URL is the absolute URL of the link. For in-page references it's
+	// the fragment as written in the page, e.g. "#note-1".
+	URL string `json:"url"`
+	// Text is the text of the link, or the alt text of its image if the
+	// link doesn't have any text.
+	Text string `json:"text"`
+	// Scope classifies the link by its destination. It's empty when the
+	// URL of the page is unknown.
+	Scope LinkScope `json:"scope,omitempty"`
+	// Rel lists the link types of the rel attribute, e.g. "nofollow",
+	// "sponsored" or "ugc", in lower case.
+	Rel []string `json:"rel,omitempty"`
+	// Fragment determines if the link is a reference to another part of
+	// the page, e.g. a footnote.
+	Fragment bool `json:"fragment"`
+}
+
+// HasRel returns true if the link has the specified link type.
+func (link ArticleLink) HasRel(rel string) bool {
+	return indexOf(link.Rel, strings.ToLower(rel)) != -1
+}
+
+// collectLinks returns the links in the article content, in the order they
+// appear.
+func (ps *parseState) collectLinks(articleContent *html.Node) []ArticleLink {
+	var links []ArticleLink
+	for _, a := range dom.GetElementsByTagName(articleContent, "a") {
+		href := strings.TrimSpace(dom.GetAttribute(a, "href"))
+		if href == "" {
+			continue
+		}
+
+		link := ArticleLink{
+			URL:      href,
+			Text:     normalizeWhitespace(dom.TextContent(a)),
+			Fragment: strings.HasPrefix(href, "#"),
+		}
+
+		if rel := strings.Fields(strings.ToLower(dom.GetAttribute(a, "rel"))); len(rel) > 0 {
+			link.Rel = rel
+		}
+
+		if link.Text == "" {
+			if imgs := dom.GetElementsByTagName(a, "img"); len(imgs) > 0 {
+				link.Text = normalizeWhitespace(dom.GetAttribute(imgs[0], "alt"))
+			}
+		}
+
+		if link.Fragment {
+			link.Scope = LinkInternal
+		} else {
+			link.Scope = linkScope(href, ps.documentURI)
+		}
+
+		links = append(links, link)
+	}
+	return links
+}
+
+// linkScope classifies the destination of href, relative to pageURL.
+func linkScope(href string, pageURL *nurl.URL) LinkScope {
+	if pageURL == nil {
+		return ""
+	}
+
+	linkURL, err := pageURL.Parse(href)
+	if err != nil {
+		return ""
+	}
+
+	if linkURL.Scheme != "http" && linkURL.Scheme != "https" {
+		return LinkOther
+	}
+
+	linkHost := strings.ToLower(linkURL.Hostname())
+	pageHost := strings.ToLower(pageURL.Hostname())
+	if linkHost == pageHost {
+		return LinkInternal
+	}
+
+	linkSite, err := publicsuffix.EffectiveTLDPlusOne(linkHost)
+	if err == nil {
+		if pageSite, err := publicsuffix.EffectiveTLDPlusOne(pageHost); err == nil && linkSite == pageSite {
+			return LinkSameSite
+		}
+	}
+
+	return LinkExternal
+}
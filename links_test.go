@@ -0,0 +1,42 @@
+package readability
+
+import (
+	nurl "net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_collectLinks(t *testing.T) {
+	page := `<html><body><article>
+<p>This story links to <a href="/other-story">another story</a>, to <a href="https://blog.example.co.uk/post">the blog</a>
+and to <a href="https://news.test/item" rel="NoFollow sponsored">a sponsor</a>, which is long enough to be content.<sup><a href="#fn1">1</a></sup></p>
+<p>Contact us by <a href="mailto:desk@example.co.uk">mail</a> or click <a href="javascript:void(0)">here</a>.
+<a href="https://www.example.co.uk/photos"><img src="/thumb.jpg" alt="Photo gallery"></a></p>
+</article></body></html>`
+
+	pageURL, _ := nurl.Parse("https://www.example.co.uk/news/story")
+	parser := NewParser()
+	parser.CharThresholds = 0
+	article, err := parser.Parse(strings.NewReader(page), pageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ArticleLink{
+		{URL: "https://www.example.co.uk/other-story", Text: "another story", Scope: LinkInternal},
+		{URL: "https://blog.example.co.uk/post", Text: "the blog", Scope: LinkSameSite},
+		{URL: "https://news.test/item", Text: "a sponsor", Scope: LinkExternal, Rel: []string{"nofollow", "sponsored"}},
+		{URL: "#fn1", Text: "1", Scope: LinkInternal, Fragment: true},
+		{URL: "mailto:desk@example.co.uk", Text: "mail", Scope: LinkOther},
+		{URL: "https://www.example.co.uk/photos", Text: "Photo gallery", Scope: LinkInternal},
+	}
+
+	if !reflect.DeepEqual(article.Links, want) {
+		t.Errorf("links\nwant: %+v\ngot : %+v", want, article.Links)
+	}
+
+	if !article.Links[2].HasRel("NOFOLLOW") || article.Links[0].HasRel("nofollow") {
+		t.Errorf("HasRel() doesn't match the rel of the links")
+	}
+}
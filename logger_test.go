@@ -0,0 +1,54 @@
+package readability
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-shiori/dom"
+)
+
+func Test_Logger(t *testing.T) {
+	f, err := os.Open("test-pages/wikipedia/source.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	doc, err := dom.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	parser := NewParser()
+	parser.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if _, err := parser.ParseAndMutate(doc, fakeHostURL); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"phase=grabArticle attempt=1",
+		"phase=prepArticle attempt=1",
+		"node=\"<div",
+		"level=DEBUG",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("log output doesn't contain %q", want)
+		}
+	}
+
+	// Nothing is logged when the logger doesn't accept debug messages.
+	buf.Reset()
+	doc, _ = dom.Parse(strings.NewReader("<p>Hello</p>"))
+	parser.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+	if _, err := parser.ParseAndMutate(doc, fakeHostURL); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("want no log output, got %q", buf.String())
+	}
+}
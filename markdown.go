@@ -0,0 +1,552 @@
+package readability
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+// Markdown renders the readable content of the article as CommonMark
+// with GitHub Flavored Markdown tables. See RenderMarkdown for details.
+func (article Article) Markdown() string {
+	if article.Node == nil {
+		return ""
+	}
+	return RenderMarkdown(article.Node)
+}
+
+// RenderMarkdown converts node and its descendants into CommonMark text.
+// Tables are rendered using the GFM table syntax, <pre> blocks become fenced
+// code blocks and links are written in reference style, with all link
+// definitions collected at the end of the document.
+func RenderMarkdown(node *html.Node) string {
+	r := &markdownRenderer{linkIndex: make(map[string]int)}
+	out := r.blocks(node)
+
+	if len(r.links) > 0 {
+		var refs strings.Builder
+		for i, href := range r.links {
+			fmt.Fprintf(&refs, "[%d]: %s\n", i+1, markdownLinkDestination(href))
+		}
+		if out != "" {
+			out += "\n\n"
+		}
+		out += strings.TrimSuffix(refs.String(), "\n")
+	}
+
+	if out == "" {
+		return ""
+	}
+	return out + "\n"
+}
+
+// markdownRenderer holds the state that is shared across the whole document
+// while converting it to Markdown, i.e. the list of referenced links.
+type markdownRenderer struct {
+	links     []string
+	linkIndex map[string]int
+}
+
+// blocks renders all children of node as a sequence of block level elements
+// separated by blank lines. Consecutive phrasing content is grouped into a
+// single paragraph.
+func (r *markdownRenderer) blocks(node *html.Node) string {
+	return strings.Join(r.blockParts(node), "\n\n")
+}
+
+// blockParts renders all children of node as a list of Markdown blocks.
+func (r *markdownRenderer) blockParts(node *html.Node) []string {
+	var parts []string
+	var inline strings.Builder
+
+	flushInline := func() {
+		if text := r.paragraph(inline.String()); text != "" {
+			parts = append(parts, text)
+		}
+		inline.Reset()
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && isBlockElement(child) {
+			flushInline()
+			if text := r.block(child); text != "" {
+				parts = append(parts, text)
+			}
+			continue
+		}
+		inline.WriteString(r.inline(child, false))
+	}
+	flushInline()
+
+	return parts
+}
+
+// block renders a single block level element.
+func (r *markdownRenderer) block(node *html.Node) string {
+	switch tag := dom.TagName(node); tag {
+	case "p":
+		return r.paragraph(r.inlineChildren(node, false))
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := collapseMarkdownSpaces(r.inlineChildren(node, true))
+		if text == "" {
+			return ""
+		}
+		level, _ := strconv.Atoi(tag[1:])
+		return strings.Repeat("#", level) + " " + text
+	case "hr":
+		return "---"
+	case "pre":
+		return r.codeBlock(node)
+	case "blockquote":
+		return prefixLines(r.blocks(node), "> ", ">")
+	case "ul", "ol":
+		return r.list(node, tag == "ol")
+	case "table":
+		return r.table(node)
+	case "figcaption":
+		return r.caption(node)
+	case "dl":
+		return r.definitionList(node)
+	case "img":
+		return r.image(node)
+	case "script", "style", "template", "noscript", "head":
+		return ""
+	default:
+		return r.blocks(node)
+	}
+}
+
+// paragraph cleans up the inline content of a paragraph, and escapes the
+// characters that would otherwise start a block construct.
+func (r *markdownRenderer) paragraph(text string) string {
+	text = collapseMarkdownSpaces(text)
+	if text == "" {
+		return ""
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = escapeMarkdownLineStart(strings.TrimLeft(line, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// codeBlock renders <pre> as a fenced code block. The language is taken from
+// a "language-*" or "lang-*" class on either the <pre> or its <code> child.
+func (r *markdownRenderer) codeBlock(node *html.Node) string {
+	language := markdownCodeLanguage(node)
+	if language == "" {
+		if codes := dom.GetElementsByTagName(node, "code"); len(codes) > 0 {
+			language = markdownCodeLanguage(codes[0])
+		}
+	}
+
+	code := strings.Trim(preText(node), "\n")
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+
+	return fence + language + "\n" + code + "\n" + fence
+}
+
+// list renders <ul> and <ol> elements. Nested blocks inside list items are
+// indented so they stay part of the item.
+func (r *markdownRenderer) list(node *html.Node, ordered bool) string {
+	start := 1
+	if ordered {
+		if n, err := strconv.Atoi(dom.GetAttribute(node, "start")); err == nil {
+			start = n
+		}
+	}
+
+	var items []string
+	for _, li := range dom.Children(node) {
+		if dom.TagName(li) != "li" {
+			continue
+		}
+
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(start+len(items)) + ". "
+		}
+
+		// Items that only contain text followed by a nested list are kept
+		// tight, so the list isn't rendered with blank lines between items.
+		separator := "\n\n"
+		if isTightListItem(li) {
+			separator = "\n"
+		}
+
+		content := strings.Join(r.blockParts(li), separator)
+		if content == "" {
+			items = append(items, strings.TrimSpace(marker))
+			continue
+		}
+
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+prefixLines(content, indent, "")[len(indent):])
+	}
+
+	return strings.Join(items, "\n")
+}
+
+// table renders a table using the GFM table syntax. The first row is used as
+// the header row, since GFM tables cannot exist without one.
+func (r *markdownRenderer) table(node *html.Node) string {
+	var rows [][]string
+	var caption string
+	columns := 0
+
+	for _, child := range dom.QuerySelectorAll(node, "caption, tr") {
+		// Ignore rows and captions that belong to nested tables.
+		if closestTable(child) != node {
+			continue
+		}
+
+		if dom.TagName(child) == "caption" {
+			caption = r.caption(child)
+			continue
+		}
+
+		var row []string
+		for _, cell := range dom.Children(child) {
+			if tag := dom.TagName(cell); tag != "td" && tag != "th" {
+				continue
+			}
+
+			text := collapseMarkdownSpaces(r.inlineChildren(cell, true))
+			text = strings.ReplaceAll(text, "|", `\|`)
+			row = append(row, text)
+		}
+
+		if len(row) > columns {
+			columns = len(row)
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 || columns == 0 {
+		return caption
+	}
+
+	var sb strings.Builder
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+
+		sb.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+		}
+	}
+
+	result := strings.TrimSuffix(sb.String(), "\n")
+	if caption != "" {
+		result = caption + "\n\n" + result
+	}
+	return result
+}
+
+// closestTable returns the nearest <table> ancestor of node.
+func closestTable(node *html.Node) *html.Node {
+	for parent := node.Parent; parent != nil; parent = parent.Parent {
+		if dom.TagName(parent) == "table" {
+			return parent
+		}
+	}
+	return nil
+}
+
+// caption renders <figcaption> and <caption> as an emphasized paragraph.
+func (r *markdownRenderer) caption(node *html.Node) string {
+	text := collapseMarkdownSpaces(r.inlineChildren(node, true))
+	if text == "" {
+		return ""
+	}
+	return "*" + text + "*"
+}
+
+// definitionList renders <dl>. Since Markdown has no equivalent, terms are
+// written in bold and their descriptions as indented blocks.
+func (r *markdownRenderer) definitionList(node *html.Node) string {
+	var parts []string
+	for _, child := range dom.Children(node) {
+		switch dom.TagName(child) {
+		case "dt":
+			if text := collapseMarkdownSpaces(r.inlineChildren(child, true)); text != "" {
+				parts = append(parts, "**"+text+"**")
+			}
+		case "dd":
+			if text := r.blocks(child); text != "" {
+				parts = append(parts, prefixLines(text, "    ", ""))
+			}
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// image renders an <img> element, using its alt text as the image
+// description and its title as the image title.
+func (r *markdownRenderer) image(node *html.Node) string {
+	src := dom.GetAttribute(node, "src")
+	if src == "" {
+		return ""
+	}
+
+	alt := escapeMarkdownText(normalizeWhitespace(dom.GetAttribute(node, "alt")))
+	title := normalizeWhitespace(dom.GetAttribute(node, "title"))
+	if title != "" {
+		return fmt.Sprintf("![%s](%s %s)", alt, markdownLinkDestination(src), strconv.Quote(title))
+	}
+	return fmt.Sprintf("![%s](%s)", alt, markdownLinkDestination(src))
+}
+
+// inlineChildren renders all children of node as phrasing content.
+func (r *markdownRenderer) inlineChildren(node *html.Node, inTable bool) string {
+	var sb strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(r.inline(child, inTable))
+	}
+	return sb.String()
+}
+
+// inline renders node as phrasing content. When inTable is true, line breaks
+// are rendered as <br> since table cells can't span multiple lines.
+func (r *markdownRenderer) inline(node *html.Node, inTable bool) string {
+	switch node.Type {
+	case html.TextNode:
+		return escapeMarkdownText(node.Data)
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch dom.TagName(node) {
+	case "br":
+		if inTable {
+			return "<br>"
+		}
+		return "\\\n"
+	case "img":
+		return r.image(node)
+	case "strong", "b":
+		return wrapMarkdownInline(r.inlineChildren(node, inTable), "**")
+	case "em", "i", "cite", "dfn":
+		return wrapMarkdownInline(r.inlineChildren(node, inTable), "*")
+	case "del", "s", "strike":
+		return wrapMarkdownInline(r.inlineChildren(node, inTable), "~~")
+	case "code", "kbd", "samp", "tt":
+		return markdownCodeSpan(dom.TextContent(node))
+	case "a":
+		return r.link(node, inTable)
+	case "script", "style", "template", "noscript":
+		return ""
+	default:
+		if isBlockElement(node) {
+			// Block elements nested inside inline content, e.g. a <div>
+			// inside a table cell, are flattened into the surrounding text.
+			return " " + r.inlineChildren(node, inTable) + " "
+		}
+		return r.inlineChildren(node, inTable)
+	}
+}
+
+// link renders <a> as a reference style link. Links without a usable href
+// are rendered as their plain content.
+func (r *markdownRenderer) link(node *html.Node, inTable bool) string {
+	content := r.inlineChildren(node, inTable)
+	text := collapseMarkdownSpaces(content)
+	href := strings.TrimSpace(dom.GetAttribute(node, "href"))
+	if href == "" || text == "" {
+		return content
+	}
+
+	idx, exist := r.linkIndex[href]
+	if !exist {
+		r.links = append(r.links, href)
+		idx = len(r.links)
+		r.linkIndex[href] = idx
+	}
+
+	return replaceMarkdownInline(content, fmt.Sprintf("[%s][%d]", text, idx))
+}
+
+// isTightListItem reports whether the only block elements inside a list item
+// are nested lists.
+func isTightListItem(li *html.Node) bool {
+	for _, child := range dom.Children(li) {
+		if tag := dom.TagName(child); isBlockElement(child) && tag != "ul" && tag != "ol" {
+			return false
+		}
+	}
+	return true
+}
+
+// isBlockElement reports whether the element is rendered as a block.
+func isBlockElement(node *html.Node) bool {
+	switch dom.TagName(node) {
+	case "address", "article", "aside", "blockquote", "dd", "details", "div",
+		"dl", "dt", "fieldset", "figcaption", "figure", "footer", "form",
+		"h1", "h2", "h3", "h4", "h5", "h6", "header", "hr", "li", "main",
+		"nav", "ol", "p", "pre", "section", "summary", "table", "ul":
+		return true
+	}
+	return false
+}
+
+// markdownCodeLanguage returns the language declared by the class name of
+// a code element, e.g. "go" from class="language-go".
+func markdownCodeLanguage(node *html.Node) string {
+	for _, class := range strings.Fields(dom.ClassName(node)) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if strings.HasPrefix(class, prefix) {
+				return strings.TrimPrefix(class, prefix)
+			}
+		}
+	}
+	return ""
+}
+
+// preText returns the text content of a <pre> element, converting
+// any <br> element into a new line.
+func preText(node *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			sb.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.Data == "br":
+			sb.WriteString("\n")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(node)
+	return sb.String()
+}
+
+// markdownCodeSpan wraps text in enough backticks so it can contain
+// backticks itself.
+func markdownCodeSpan(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return ""
+	}
+
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+	return fence + text + fence
+}
+
+// markdownLinkDestination makes sure a URL can be used as link destination,
+// wrapping it in angle brackets when it contains spaces or parentheses.
+func markdownLinkDestination(href string) string {
+	if strings.ContainsAny(href, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(href) + ">"
+	}
+	return href
+}
+
+// wrapMarkdownInline wraps the non-space part of text with delimiter,
+// keeping the surrounding whitespace outside so the emphasis stays valid.
+func wrapMarkdownInline(text, delimiter string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	return replaceMarkdownInline(text, delimiter+trimmed+delimiter)
+}
+
+// replaceMarkdownInline replaces the non-space part of text with
+// replacement, keeping its leading and trailing whitespace.
+func replaceMarkdownInline(text, replacement string) string {
+	trimmed := strings.TrimSpace(text)
+	start := strings.Index(text, trimmed)
+	return text[:start] + replacement + text[start+len(trimmed):]
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+)
+
+// escapeMarkdownText escapes characters that have an inline meaning in
+// Markdown.
+func escapeMarkdownText(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// escapeMarkdownLineStart escapes characters at the beginning of a line
+// which would otherwise be interpreted as a heading, quote or list.
+func escapeMarkdownLineStart(line string) string {
+	switch {
+	case line == "":
+		return line
+	case strings.HasPrefix(line, "#"), strings.HasPrefix(line, ">"),
+		strings.HasPrefix(line, "- "), strings.HasPrefix(line, "+ "),
+		strings.HasPrefix(line, "="), line == "-":
+		return `\` + line
+	}
+
+	// Ordered list markers, e.g. "1. " or "1) "
+	digits := 0
+	for digits < len(line) && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 && digits < len(line) && (line[digits] == '.' || line[digits] == ')') &&
+		(digits+1 == len(line) || line[digits+1] == ' ') {
+		return line[:digits] + `\` + line[digits:]
+	}
+
+	return line
+}
+
+// collapseMarkdownSpaces collapses runs of whitespace into a single space
+// while keeping hard line breaks intact.
+func collapseMarkdownSpaces(text string) string {
+	lines := strings.Split(text, "\\\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+
+	// Drop hard breaks at the start or the end of the text.
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\\\n")
+}
+
+// prefixLines prefixes every line of text. Empty lines are prefixed
+// with emptyPrefix instead.
+func prefixLines(text, prefix, emptyPrefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = emptyPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
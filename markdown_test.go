@@ -0,0 +1,71 @@
+package readability
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-shiori/dom"
+)
+
+func Test_RenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "paragraphs and emphasis",
+			html: `<p>Hello <b>bold</b> and <em>italic</em> text.</p><p>Second   paragraph</p>`,
+			want: "Hello **bold** and *italic* text.\n\nSecond paragraph\n",
+		},
+		{
+			name: "headings and escaping",
+			html: `<h2>Title with *stars*</h2><p># not a heading</p>`,
+			want: "## Title with \\*stars\\*\n\n\\# not a heading\n",
+		},
+		{
+			name: "reference links",
+			html: `<p>See <a href="http://a.com/x">this</a> and <a href="http://a.com/x">that</a> or <a href="http://b.com">other</a>.</p>`,
+			want: "See [this][1] and [that][1] or [other][2].\n\n[1]: http://a.com/x\n[2]: http://b.com\n",
+		},
+		{
+			name: "fenced code",
+			html: "<pre><code class=\"language-go\">func main() {\n\tfmt.Println(\"```\")\n}</code></pre>",
+			want: "````go\nfunc main() {\n\tfmt.Println(\"```\")\n}\n````\n",
+		},
+		{
+			name: "nested lists",
+			html: `<ul><li>One<ul><li>Nested</li></ul></li><li>Two</li></ul><ol start="3"><li>Three</li><li>Four</li></ol>`,
+			want: "- One\n  - Nested\n- Two\n\n3. Three\n4. Four\n",
+		},
+		{
+			name: "figure with caption",
+			html: `<figure><img src="http://a.com/i.png" alt="An image"><figcaption>The caption</figcaption></figure>`,
+			want: "![An image](http://a.com/i.png)\n\n*The caption*\n",
+		},
+		{
+			name: "data table",
+			html: `<table><thead><tr><th>Name</th><th>Value</th></tr></thead><tbody><tr><td>a|b</td><td>1</td></tr><tr><td>c</td></tr></tbody></table>`,
+			want: "| Name | Value |\n| --- | --- |\n| a\\|b | 1 |\n| c |  |\n",
+		},
+		{
+			name: "blockquote",
+			html: `<blockquote><p>Quoted</p><p>Text</p></blockquote>`,
+			want: "> Quoted\n>\n> Text\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := dom.Parse(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body := dom.GetElementsByTagName(doc, "body")[0]
+			if got := RenderMarkdown(body); got != tt.want {
+				t.Errorf("RenderMarkdown()\nwant: %q\ngot : %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package readability
+
+import (
+	"strings"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+// itemSyntax describes how the items of a structured data syntax embedded
+// in HTML attributes, i.e. microdata or RDFa, are written.
+type itemSyntax struct {
+	// scope is the attribute of the element of an item.
+	scope string
+	// types is the attribute that lists the types of an item.
+	types string
+	// prop is the attribute that lists the property names of an element.
+	prop string
+	// prefixed determines if the types can be written with a prefix or
+	// relative to the vocab attribute, like in RDFa.
+	prefixed bool
+	// value returns the value of a property element.
+	value func(*html.Node) string
+}
+
+var (
+	microdataSyntax = itemSyntax{
+		scope: "itemscope",
+		types: "itemtype",
+		prop:  "itemprop",
+		value: microdataValue,
+	}
+	rdfaSyntax = itemSyntax{
+		scope:    "typeof",
+		types:    "typeof",
+		prop:     "property",
+		prefixed: true,
+		value:    rdfaValue,
+	}
+)
+
+// getItemMetadata extracts the metadata of the Schema.org article item of
+// the page, written in microdata or in RDFa, depending on syntax. The keys
+// are the same as the ones of the JSON-LD metadata.
+func (ps *parseState) getItemMetadata(syntax itemSyntax) map[string]string {
+	item := syntax.findArticle(ps.doc)
+	if item == nil {
+		return nil
+	}
+
+	metadata := syntax.metadata(item)
+	if metadata["image"] != "" {
+		metadata["image"] = toAbsoluteURI(metadata["image"], ps.documentURI)
+	}
+	return metadata
+}
+
+// findArticle returns the first item of doc whose type is Article or one of
+// its subtypes.
+func (s itemSyntax) findArticle(doc *html.Node) *html.Node {
+	for _, node := range dom.GetElementsByTagName(doc, "*") {
+		if !dom.HasAttribute(node, s.scope) {
+			continue
+		}
+
+		for _, itemType := range strings.Fields(dom.GetAttribute(node, s.types)) {
+			if name, ok := s.schemaOrgName(node, itemType); ok && rxJsonLdArticleTypes.MatchString(name) {
+				return node
+			}
+		}
+	}
+	return nil
+}
+
+// schemaOrgName returns the name of a Schema.org type, which is written as
+// a URL, e.g. "https://schema.org/NewsArticle". In RDFa it may also be
+// written with the "schema:" prefix, or as a bare name if the vocabulary
+// of node is Schema.org.
+func (s itemSyntax) schemaOrgName(node *html.Node, value string) (string, bool) {
+	if idx := strings.Index(value, "schema.org/"); idx >= 0 {
+		return strings.Trim(value[idx+len("schema.org/"):], "/"), true
+	}
+
+	if !s.prefixed {
+		return "", false
+	}
+
+	if name, found := strings.CutPrefix(value, "schema:"); found {
+		return name, true
+	}
+
+	for parent := node; parent != nil; parent = parent.Parent {
+		if vocab := dom.GetAttribute(parent, "vocab"); vocab != "" {
+			return value, strings.Contains(vocab, "schema.org")
+		}
+	}
+	return "", false
+}
+
+// properties returns the property elements of item, by property name. The
+// properties of the items nested in item are excluded.
+func (s itemSyntax) properties(item *html.Node) map[string][]*html.Node {
+	props := make(map[string][]*html.Node)
+
+	var collect func(*html.Node)
+	collect = func(node *html.Node) {
+		for _, child := range dom.Children(node) {
+			names := strings.Fields(dom.GetAttribute(child, s.prop))
+			for _, name := range names {
+				name = propertyName(name)
+				props[name] = append(props[name], child)
+			}
+
+			// A nested item is the value of its property, if any, and
+			// its own properties aren't part of item
+			if !dom.HasAttribute(child, s.scope) {
+				collect(child)
+			}
+		}
+	}
+	collect(item)
+
+	return props
+}
+
+// metadata returns the metadata of an article item.
+func (s itemSyntax) metadata(item *html.Node) map[string]string {
+	props := s.properties(item)
+	metadata := make(map[string]string)
+
+	metadata["title"] = strOr(itemText(props["headline"]), itemText(props["name"]))
+
+	var authors []string
+	for _, author := range props["author"] {
+		if name := s.name(author); name != "" && indexOf(authors, name) == -1 {
+			authors = append(authors, name)
+		}
+	}
+	metadata["byline"] = strings.Join(authors, ", ")
+
+	if len(props["publisher"]) > 0 {
+		metadata["siteName"] = s.name(props["publisher"][0])
+	}
+
+	if len(props["image"]) > 0 {
+		image := props["image"][0]
+		if dom.HasAttribute(image, s.scope) {
+			imageProps := s.properties(image)
+			metadata["image"] = strOr(s.first(imageProps["url"]), s.first(imageProps["contentUrl"]))
+		} else {
+			metadata["image"] = s.value(image)
+		}
+	}
+
+	metadata["datePublished"] = s.first(props["datePublished"])
+	metadata["dateModified"] = s.first(props["dateModified"])
+
+	for key, value := range metadata {
+		if value == "" {
+			delete(metadata, key)
+		}
+	}
+	return metadata
+}
+
+// name returns the name of a person or an organization, which is either
+// a nested item or the value of the property element itself.
+func (s itemSyntax) name(node *html.Node) string {
+	if dom.HasAttribute(node, s.scope) {
+		return itemText(s.properties(node)["name"])
+	}
+	return itemText([]*html.Node{node})
+}
+
+// first returns the first non empty value of nodes.
+func (s itemSyntax) first(nodes []*html.Node) string {
+	for _, node := range nodes {
+		if value := s.value(node); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// itemText returns the first non empty text of nodes. Unlike the value of
+// a property, the text of a link is its content rather than its URL.
+func itemText(nodes []*html.Node) string {
+	for _, node := range nodes {
+		text := dom.GetAttribute(node, "content")
+		if text == "" {
+			text = dom.TextContent(node)
+		}
+		if text = normalizeWhitespace(text); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// propertyName returns the name of a property without its Schema.org
+// prefix, e.g. "headline" for "schema:headline" or for
+// "https://schema.org/headline".
+func propertyName(name string) string {
+	if idx := strings.LastIndexAny(name, "/:#"); idx >= 0 {
+		return name[idx+1:]
+	}
+	return name
+}
+
+// microdataValue returns the value of a microdata property element, as
+// defined by the HTML specification.
+func microdataValue(node *html.Node) string {
+	var value string
+	switch dom.TagName(node) {
+	case "meta":
+		value = dom.GetAttribute(node, "content")
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		value = dom.GetAttribute(node, "src")
+	case "a", "area", "link":
+		value = dom.GetAttribute(node, "href")
+	case "object":
+		value = dom.GetAttribute(node, "data")
+	case "data", "meter":
+		value = dom.GetAttribute(node, "value")
+	case "time":
+		if dom.HasAttribute(node, "datetime") {
+			value = dom.GetAttribute(node, "datetime")
+		} else {
+			value = dom.TextContent(node)
+		}
+	default:
+		value = dom.TextContent(node)
+	}
+	return normalizeWhitespace(value)
+}
+
+// rdfaValue returns the value of a RDFa property element.
+func rdfaValue(node *html.Node) string {
+	var value string
+	switch {
+	case dom.HasAttribute(node, "content"):
+		value = dom.GetAttribute(node, "content")
+	case dom.HasAttribute(node, "datetime"):
+		value = dom.GetAttribute(node, "datetime")
+	case dom.HasAttribute(node, "resource"):
+		value = dom.GetAttribute(node, "resource")
+	case dom.HasAttribute(node, "href"):
+		value = dom.GetAttribute(node, "href")
+	case dom.HasAttribute(node, "src"):
+		value = dom.GetAttribute(node, "src")
+	default:
+		value = dom.TextContent(node)
+	}
+	return normalizeWhitespace(value)
+}
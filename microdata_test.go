@@ -0,0 +1,120 @@
+package readability
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-shiori/dom"
+)
+
+func Test_getItemMetadata(t *testing.T) {
+	tests := []struct {
+		name   string
+		syntax itemSyntax
+		html   string
+		want   map[string]string
+	}{
+		{
+			name:   "microdata",
+			syntax: microdataSyntax,
+			html: `<article itemscope itemtype="https://schema.org/NewsArticle">
+<h1 itemprop="headline">Council Approves the <em>New</em> Library</h1>
+<p>By <span itemprop="author" itemscope itemtype="https://schema.org/Person"><a itemprop="url" href="/jane"><span itemprop="name">Jane Doe</span></a></span>
+and <span itemprop="author">John Roe</span>,
+<time itemprop="datePublished" datetime="2024-05-17T08:00:00Z">May 17</time></p>
+<meta itemprop="dateModified" content="2024-05-18T09:30:00Z">
+<div itemprop="image" itemscope itemtype="https://schema.org/ImageObject"><img itemprop="url" src="/library.jpg"></div>
+<div itemprop="publisher" itemscope itemtype="https://schema.org/Organization"><meta itemprop="name" content="Example News"></div>
+<div itemscope itemtype="https://schema.org/Comment"><span itemprop="author">Commenter</span></div>
+</article>`,
+			want: map[string]string{
+				"title":         "Council Approves the New Library",
+				"byline":        "Jane Doe, John Roe",
+				"datePublished": "2024-05-17T08:00:00Z",
+				"dateModified":  "2024-05-18T09:30:00Z",
+				"image":         "http://fakehost/library.jpg",
+				"siteName":      "Example News",
+			},
+		},
+		{
+			name:   "rdfa",
+			syntax: rdfaSyntax,
+			html: `<div vocab="https://schema.org/"><article typeof="BlogPosting">
+<h1 property="headline">Notes on Gardening</h1>
+<span property="author" typeof="Person"><span property="name">Ann Lee</span></span>
+<time property="datePublished" datetime="2023-03-01">March 1</time>
+<img property="image" src="https://cdn.example.com/garden.jpg">
+<span property="schema:publisher" typeof="Organization"><span property="name">Garden Blog</span></span>
+</article></div>`,
+			want: map[string]string{
+				"title":         "Notes on Gardening",
+				"byline":        "Ann Lee",
+				"datePublished": "2023-03-01",
+				"image":         "https://cdn.example.com/garden.jpg",
+				"siteName":      "Garden Blog",
+			},
+		},
+		{
+			name:   "rdfa without schema.org vocabulary",
+			syntax: rdfaSyntax,
+			html:   `<article vocab="http://purl.org/dc/terms/" typeof="Article"><h1 property="title">Title</h1></article>`,
+			want:   nil,
+		},
+		{
+			name:   "not an article",
+			syntax: microdataSyntax,
+			html:   `<div itemscope itemtype="https://schema.org/Product"><span itemprop="name">Lamp</span></div>`,
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := dom.Parse(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parser := NewParser()
+			ps := &parseState{Parser: &parser, doc: doc, documentURI: fakeHostURL}
+			if got := ps.getItemMetadata(tt.syntax); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("metadata\nwant: %v\ngot : %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_Article_microdataTitle(t *testing.T) {
+	const item = `<article itemscope itemtype="https://schema.org/NewsArticle"><meta itemprop="headline" content="Council Approves the New Library">
+<p>The council met on Tuesday evening to discuss the new budget, which includes more money for public schools and libraries.</p></article>`
+
+	tests := []struct {
+		name       string
+		head       string
+		want       string
+		wantSource string
+	}{
+		{"before <title>", `<title>Library approved | City News</title>`, "Council Approves the New Library", "microdata"},
+		{"after og:title", `<title>Library approved | City News</title><meta property="og:title" content="Library Approved">`, "Library Approved", "og:title"},
+		{"without <title>", ``, "Council Approves the New Library", "microdata"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser()
+			parser.CharThresholds = 0
+			article, err := parser.Parse(strings.NewReader(`<html><head>`+tt.head+`</head><body>`+item+`</body></html>`), fakeHostURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if article.Title != tt.want {
+				t.Errorf("title, want %q got %q", tt.want, article.Title)
+			}
+			if source := article.MetadataSources["title"]; source != tt.wantSource {
+				t.Errorf("title source, want %q got %q", tt.wantSource, source)
+			}
+		})
+	}
+}
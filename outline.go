@@ -0,0 +1,136 @@
+package readability
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+// OutlineItem is a heading of the article content, with the headings of its
+// subsections.
+type OutlineItem struct {
+	// Level is the level of the heading, from 1 for <h1> to 6 for <h6>.
+	// Since the <h1> of the content are converted to <h2>, the top level
+	// is usually 2.
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	// ID is the fragment that links to the heading. It's the id of the
+	// heading or of an anchor inside it if there's one, e.g. the <span id>
+	// of Wikipedia, or else a slug of Text, e.g. "early-life". It's unique
+	// within the content.
+	ID       string        `json:"id"`
+	Children []OutlineItem `json:"children,omitempty"`
+}
+
+// getOutline returns the tree of the headings of the article content. The
+// generated ids are set on the headings if AddHeadingIDs is enabled.
+func (ps *parseState) getOutline(articleContent *html.Node) []OutlineItem {
+	usedIDs := make(map[string]struct{})
+	for _, node := range dom.QuerySelectorAll(articleContent, "[id]") {
+		usedIDs[dom.ID(node)] = struct{}{}
+	}
+
+	var headings []OutlineItem
+	for _, heading := range dom.QuerySelectorAll(articleContent, "h1, h2, h3, h4, h5, h6") {
+		item := OutlineItem{
+			Level: int(dom.TagName(heading)[1] - '0'),
+			Text:  normalizeWhitespace(dom.TextContent(heading)),
+			ID:    dom.ID(heading),
+		}
+
+		// Use the anchor inside the heading, whose text is usually the
+		// title of the section without the extra links, e.g. "[edit]".
+		if item.ID == "" {
+			if anchor := headingAnchor(heading); anchor != nil {
+				item.ID = strOr(dom.ID(anchor), dom.GetAttribute(anchor, "name"))
+				if text := normalizeWhitespace(dom.TextContent(anchor)); text != "" {
+					item.Text = text
+				}
+			}
+		}
+
+		if item.Text == "" {
+			continue
+		}
+
+		if item.ID == "" {
+			item.ID = uniqueSlug(item.Text, usedIDs)
+			if ps.AddHeadingIDs {
+				dom.SetAttribute(heading, "id", item.ID)
+			}
+		}
+
+		headings = append(headings, item)
+	}
+
+	return buildOutline(headings)
+}
+
+// headingAnchor returns the first element inside heading that can be the
+// target of a link, i.e. that has an id or that is a named anchor.
+func headingAnchor(heading *html.Node) *html.Node {
+	for _, node := range dom.QuerySelectorAll(heading, "[id], a[name]") {
+		if dom.ID(node) != "" || dom.GetAttribute(node, "name") != "" {
+			return node
+		}
+	}
+	return nil
+}
+
+// buildOutline nests the headings, which are in document order, under the
+// previous heading of a lower level.
+func buildOutline(headings []OutlineItem) []OutlineItem {
+	i := 0
+
+	var build func(level int) []OutlineItem
+	build = func(level int) []OutlineItem {
+		var items []OutlineItem
+		for i < len(headings) && headings[i].Level > level {
+			item := headings[i]
+			i++
+			item.Children = build(item.Level)
+			items = append(items, item)
+		}
+		return items
+	}
+
+	return build(0)
+}
+
+// uniqueSlug returns the slug of text, e.g. "early-life" for "Early life",
+// suffixed by a number if it's already in usedIDs. The returned slug is
+// added to usedIDs.
+func uniqueSlug(text string, usedIDs map[string]struct{}) string {
+	var sb strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(text) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pendingDash = sb.Len() > 0
+			continue
+		}
+		if pendingDash {
+			sb.WriteByte('-')
+			pendingDash = false
+		}
+		sb.WriteRune(r)
+	}
+
+	base := sb.String()
+	if base == "" {
+		base = "section"
+	}
+
+	slug := base
+	for n := 1; ; n++ {
+		if _, used := usedIDs[slug]; !used {
+			break
+		}
+		slug = base + "-" + strconv.Itoa(n)
+	}
+
+	usedIDs[slug] = struct{}{}
+	return slug
+}
@@ -0,0 +1,82 @@
+package readability
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-shiori/dom"
+)
+
+func Test_getOutline(t *testing.T) {
+	content := `<div>
+<h2>Early life</h2>
+<h3>Childhood</h3>
+<h4>School &amp; friends</h4>
+<h3 id="youth">Youth</h3>
+<h2><span id="Career">Career</span><span>[edit]</span></h2>
+<h3><a name="start"></a>First job</h3>
+<h2>Early   life</h2>
+<h2></h2>
+<h2>¿Qué?</h2>
+<h2>!!!</h2>
+</div>`
+
+	want := []OutlineItem{
+		{Level: 2, Text: "Early life", ID: "early-life", Children: []OutlineItem{
+			{Level: 3, Text: "Childhood", ID: "childhood", Children: []OutlineItem{
+				{Level: 4, Text: "School & friends", ID: "school-friends"},
+			}},
+			{Level: 3, Text: "Youth", ID: "youth"},
+		}},
+		{Level: 2, Text: "Career", ID: "Career", Children: []OutlineItem{
+			{Level: 3, Text: "First job", ID: "start"},
+		}},
+		{Level: 2, Text: "Early life", ID: "early-life-1"},
+		{Level: 2, Text: "¿Qué?", ID: "qué"},
+		{Level: 2, Text: "!!!", ID: "section"},
+	}
+
+	for _, addIDs := range []bool{false, true} {
+		doc, err := dom.Parse(strings.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		articleContent := dom.QuerySelector(doc, "div")
+
+		parser := NewParser()
+		parser.AddHeadingIDs = addIDs
+		ps := &parseState{Parser: &parser}
+		outline := ps.getOutline(articleContent)
+		if !reflect.DeepEqual(outline, want) {
+			t.Errorf("outline, add ids %v\nwant: %+v\ngot : %+v", addIDs, want, outline)
+		}
+
+		var ids []string
+		for _, heading := range dom.QuerySelectorAll(articleContent, "h2, h3, h4") {
+			ids = append(ids, dom.ID(heading))
+		}
+
+		wantIDs := []string{"", "", "", "youth", "", "", "", "", "", ""}
+		if addIDs {
+			wantIDs = []string{"early-life", "childhood", "school-friends", "youth", "", "", "early-life-1", "", "qué", "section"}
+		}
+		if !reflect.DeepEqual(ids, wantIDs) {
+			t.Errorf("heading ids, add ids %v\nwant: %q\ngot : %q", addIDs, wantIDs, ids)
+		}
+	}
+}
+
+func Test_getOutline_existingIDs(t *testing.T) {
+	doc, err := dom.Parse(strings.NewReader(`<div><p id="intro">Text</p><h2>Intro</h2></div>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser := NewParser()
+	ps := &parseState{Parser: &parser}
+	outline := ps.getOutline(dom.QuerySelector(doc, "div"))
+	if len(outline) != 1 || outline[0].ID != "intro-1" {
+		t.Errorf("want id %q got %+v", "intro-1", outline)
+	}
+}
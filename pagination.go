@@ -0,0 +1,297 @@
+package readability
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	nurl "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+var (
+	rxNextLink        = regexp.MustCompile(`(?i)(next|weiter|continue|suivant|siguiente|下一页|次へ|далее|>([^\|]|$)|»([^\|]|$))`)
+	rxPrevLink        = regexp.MustCompile(`(?i)(prev|earl|old|new|<|«)`)
+	rxPaginationClass = regexp.MustCompile(`(?i)pagination|pager|paging`)
+)
+
+// defaultMaxPages is the max number of stitched pages when MaxPages is 0.
+const defaultMaxPages = 10
+
+// PageFetcher retrieves the document located at pageURL. It returns the body
+// of the document and its content type, which is used to detect the charset
+// of the document. The caller is responsible for closing the returned body.
+type PageFetcher func(ctx context.Context, pageURL *nurl.URL) (io.ReadCloser, string, error)
+
+// HTTPPageFetcher returns a PageFetcher that fetches pages using client. If
+// client is nil, http.DefaultClient is used.
+func HTTPPageFetcher(client *http.Client, requestModifiers ...RequestWith) PageFetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return func(ctx context.Context, pageURL *nurl.URL) (io.ReadCloser, string, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", pageURL.String(), nil)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, modifier := range requestModifiers {
+			modifier(req)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			resp.Body.Close()
+			return nil, "", fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+
+		return resp.Body, resp.Header.Get("Content-Type"), nil
+	}
+}
+
+// appendNextPages follows the "next page" links starting from the current
+// document, extracts the content of every subsequent page and appends it to
+// articleContent as "readability-page-N". Content that was already seen on
+// previous pages, e.g. repeated intros, is removed from subsequent pages.
+// It returns the number of pages that were appended.
+func (ps *parseState) appendNextPages(ctx context.Context, articleContent *html.Node) int {
+	if ps.documentURI == nil {
+		return 0
+	}
+
+	visited := map[string]struct{}{pageKey(ps.documentURI): {}}
+	seenBlocks := make(map[string]struct{})
+	collectBlockTexts(articleContent, seenBlocks)
+
+	maxPages := ps.MaxPages
+	if maxPages == 0 {
+		maxPages = defaultMaxPages
+	}
+
+	appended := 0
+	doc, pageURL := ps.doc, ps.documentURI
+	for pageNum := 2; maxPages < 0 || pageNum <= maxPages; pageNum++ {
+		nextURL := ps.findNextPageURL(doc, pageURL, pageNum-1, visited)
+		if nextURL == nil {
+			return appended
+		}
+		visited[pageKey(nextURL)] = struct{}{}
+
+		if err := ctx.Err(); err != nil {
+			ps.logf("stop fetching pages: %v\n", err)
+			return appended
+		}
+
+		ps.logf("fetching page %d: %s\n", pageNum, nextURL)
+		nextDoc, page, err := ps.extractPage(ctx, nextURL)
+		if err != nil {
+			ps.logf("failed to extract page %d: %v\n", pageNum, err)
+			return appended
+		}
+
+		removeDuplicateBlocks(page, seenBlocks)
+		if !hasTextContent(page) {
+			ps.logf("page %d has no new content\n", pageNum)
+			return appended
+		}
+		collectBlockTexts(page, seenBlocks)
+
+		dom.SetAttribute(page, "id", "readability-page-"+strconv.Itoa(pageNum))
+		dom.SetAttribute(page, "class", "page")
+		dom.AppendChild(articleContent, page)
+		appended++
+
+		doc, pageURL = nextDoc, nextURL
+	}
+	return appended
+}
+
+// extractPage fetches the page at pageURL and extracts its readable content.
+// It returns the parsed document, so it can be searched for the next link,
+// and the element that wraps the page content.
+func (ps *parseState) extractPage(ctx context.Context, pageURL *nurl.URL) (*html.Node, *html.Node, error) {
+	body, contentType, err := ps.PageFetcher(ctx, pageURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer body.Close()
+
+	content, _, err := decodeHTML(body, contentType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err := dom.Parse(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Subsequent pages are extracted using the same configuration, except
+	// that they shouldn't follow their own next links. The extraction
+	// mutates its document, so it's done on a copy to keep the original
+	// for finding the next link.
+	pageParser := *ps.Parser
+	pageParser.PageFetcher = nil
+
+	pageState := pageParser.newParseState(dom.Clone(doc, true), pageURL)
+	article, err := pageState.parseAndMutate(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for url := range pageState.noscriptImages {
+		ps.noscriptImages[url] = struct{}{}
+	}
+	if article.Node == nil {
+		return nil, nil, fmt.Errorf("no readable content")
+	}
+
+	// The extracted content might not be wrapped in a page element, e.g.
+	// when the whole body was used as the top candidate.
+	page := article.Node
+	if dom.ID(page) != "readability-page-1" {
+		page = dom.CreateElement("div")
+		for article.Node.Parent.FirstChild != nil {
+			dom.AppendChild(page, article.Node.Parent.FirstChild)
+		}
+	}
+	dom.DetachChild(page)
+
+	return doc, page, nil
+}
+
+// findNextPageURL finds the link to the page that follows the current one,
+// either declared with rel="next" or guessed from the text of the links in
+// the document. It returns nil if there is no next page, or if the next page
+// was already visited.
+func (ps *parseState) findNextPageURL(doc *html.Node, pageURL *nurl.URL, pageNum int, visited map[string]struct{}) *nurl.URL {
+	// Pages that declare their next page explicitly are the most reliable.
+	for _, link := range dom.QuerySelectorAll(doc, `link[rel~="next"], a[rel~="next"]`) {
+		if nextURL := ps.nextPageCandidate(link, pageURL, visited); nextURL != nil {
+			return nextURL
+		}
+	}
+
+	var bestURL *nurl.URL
+	bestScore := 0
+	nextPageNum := strconv.Itoa(pageNum + 1)
+
+	for _, link := range dom.GetElementsByTagName(doc, "a") {
+		nextURL := ps.nextPageCandidate(link, pageURL, visited)
+		if nextURL == nil {
+			continue
+		}
+
+		linkText := normalizeWhitespace(dom.TextContent(link))
+		if charCount(linkText) > 25 {
+			continue
+		}
+
+		inPagination := ps.isInPagination(link)
+
+		score := 0
+		if rxNextLink.MatchString(linkText) {
+			score += 50
+		}
+		if rxPrevLink.MatchString(linkText) {
+			score -= 200
+		}
+		if linkText == nextPageNum && inPagination {
+			score += 50
+		}
+		if inPagination {
+			score += 25
+		}
+
+		if score >= 50 && score > bestScore {
+			bestURL, bestScore = nextURL, score
+		}
+	}
+
+	return bestURL
+}
+
+// nextPageCandidate resolves the href of link against pageURL, and returns it
+// if it could point to the next page of the same article.
+func (ps *parseState) nextPageCandidate(link *html.Node, pageURL *nurl.URL, visited map[string]struct{}) *nurl.URL {
+	href := strings.TrimSpace(dom.GetAttribute(link, "href"))
+	if href == "" || strings.HasPrefix(href, "#") {
+		return nil
+	}
+
+	nextURL, err := pageURL.Parse(href)
+	if err != nil || (nextURL.Scheme != "http" && nextURL.Scheme != "https") {
+		return nil
+	}
+
+	// Pages of the same article are always hosted on the same site.
+	if !strings.EqualFold(nextURL.Hostname(), pageURL.Hostname()) {
+		return nil
+	}
+
+	if _, seen := visited[pageKey(nextURL)]; seen {
+		return nil
+	}
+	return nextURL
+}
+
+// isInPagination reports whether the link is located inside an element that
+// looks like pagination, based on the class names and IDs of its ancestors.
+func (ps *parseState) isInPagination(link *html.Node) bool {
+	node := link
+	for depth := 0; node != nil && depth < 4; depth++ {
+		if rxPaginationClass.MatchString(dom.ClassName(node) + " " + dom.ID(node)) {
+			return true
+		}
+		node = node.Parent
+	}
+	return false
+}
+
+// pageKey returns the key used to identify a page, ignoring its fragment.
+func pageKey(pageURL *nurl.URL) string {
+	u := *pageURL
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String()
+}
+
+// duplicateBlockTags are the elements compared across pages to detect content
+// that is repeated on every page.
+var duplicateBlockTags = []string{"p", "h2", "h3", "h4", "h5", "h6", "li", "blockquote", "pre", "figure"}
+
+// collectBlockTexts saves the normalized text of all blocks in node into seen.
+func collectBlockTexts(node *html.Node, seen map[string]struct{}) {
+	for _, tag := range duplicateBlockTags {
+		for _, block := range dom.GetElementsByTagName(node, tag) {
+			if text := normalizeWhitespace(dom.TextContent(block)); text != "" {
+				seen[text] = struct{}{}
+			}
+		}
+	}
+}
+
+// removeDuplicateBlocks removes all blocks in node whose text is in seen.
+func removeDuplicateBlocks(node *html.Node, seen map[string]struct{}) {
+	for _, tag := range duplicateBlockTags {
+		for _, block := range dom.GetElementsByTagName(node, tag) {
+			if block.Parent == nil {
+				continue
+			}
+			text := normalizeWhitespace(dom.TextContent(block))
+			if _, exist := seen[text]; exist && text != "" {
+				block.Parent.RemoveChild(block)
+			}
+		}
+	}
+}
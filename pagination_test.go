@@ -0,0 +1,145 @@
+package readability
+
+import (
+	"context"
+	"fmt"
+	"io"
+	nurl "net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-shiori/dom"
+)
+
+func Test_appendNextPages(t *testing.T) {
+	const intro = "This introduction is repeated on top of every single page of the story, which is why it must only appear once."
+
+	paragraphs := func(page int) string {
+		var sb strings.Builder
+		for i := 1; i <= 4; i++ {
+			fmt.Fprintf(&sb, "<p>Paragraph %d of page %d, which has enough words, commas, and sentences to be scored as content by the parser. "+
+				"It keeps going for a while so that the page passes the character threshold.</p>", i, page)
+		}
+		return sb.String()
+	}
+
+	pages := map[string]string{
+		"http://fakehost/story": `<html><head><title>Story</title><link rel="next" href="/story?page=2"></head><body>
+			<article><p>` + intro + `</p>` + paragraphs(1) + `</article></body></html>`,
+		"http://fakehost/story?page=2": `<html><head><title>Story</title></head><body>
+			<article><p>` + intro + `</p>` + paragraphs(2) + `</article>
+			<div class="pagination"><a href="/story">1</a> <a href="/story?page=3">3</a></div></body></html>`,
+		"http://fakehost/story?page=3": `<html><head><title>Story</title></head><body>
+			<article><p>` + intro + `</p>` + paragraphs(3) + `</article>
+			<div class="pagination"><a href="/story?page=2">Previous</a> <a href="http://otherhost/">Next</a></div></body></html>`,
+	}
+
+	var fetched []string
+	fetcher := func(ctx context.Context, pageURL *nurl.URL) (io.ReadCloser, string, error) {
+		fetched = append(fetched, pageURL.String())
+		content, ok := pages[pageURL.String()]
+		if !ok {
+			return nil, "", fmt.Errorf("not found: %s", pageURL)
+		}
+		return io.NopCloser(strings.NewReader(content)), "text/html", nil
+	}
+
+	pageURL, _ := nurl.Parse("http://fakehost/story")
+
+	t.Run("stitch pages", func(t *testing.T) {
+		fetched = nil
+		parser := NewParser()
+		parser.PageFetcher = fetcher
+
+		article, err := parser.Parse(strings.NewReader(pages[pageURL.String()]), pageURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want := []string{"http://fakehost/story?page=2", "http://fakehost/story?page=3"}; fmt.Sprint(fetched) != fmt.Sprint(want) {
+			t.Errorf("fetched pages, want %v got %v", want, fetched)
+		}
+
+		for i := 1; i <= 3; i++ {
+			id := fmt.Sprintf("readability-page-%d", i)
+			if dom.GetElementByID(article.Node, id) == nil {
+				t.Errorf("missing %s in node", id)
+			}
+
+			text := fmt.Sprintf("Paragraph 4 of page %d", i)
+			if !strings.Contains(article.TextContent, text) {
+				t.Errorf("missing %q in text content", text)
+			}
+			if !strings.Contains(article.Text(TextOptions{}), text) {
+				t.Errorf("missing %q in text", text)
+			}
+			if !strings.Contains(article.Markdown(), text) {
+				t.Errorf("missing %q in markdown", text)
+			}
+		}
+
+		if n := strings.Count(article.TextContent, intro); n != 1 {
+			t.Errorf("repeated intro, want 1 occurrence got %d", n)
+		}
+	})
+
+	t.Run("page limit", func(t *testing.T) {
+		fetched = nil
+		parser := NewParser()
+		parser.PageFetcher = fetcher
+		parser.MaxPages = 2
+
+		article, err := parser.Parse(strings.NewReader(pages[pageURL.String()]), pageURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(fetched) != 1 {
+			t.Errorf("want 1 fetched page, got %v", fetched)
+		}
+		if strings.Contains(article.TextContent, "of page 3") {
+			t.Error("content of page 3 should not be included")
+		}
+	})
+	// A story of 15 pages, whose every page links to the next one
+	const longStoryPages = 15
+	longStory := func(ctx context.Context, pageURL *nurl.URL) (io.ReadCloser, string, error) {
+		fetched = append(fetched, pageURL.String())
+		page := 1
+		fmt.Sscanf(pageURL.RawQuery, "page=%d", &page)
+
+		var next string
+		if page < longStoryPages {
+			next = fmt.Sprintf(`<link rel="next" href="/story?page=%d">`, page+1)
+		}
+		content := `<html><head><title>Story</title>` + next + `</head><body><article>` + paragraphs(page) + `</article></body></html>`
+		return io.NopCloser(strings.NewReader(content)), "text/html", nil
+	}
+
+	limits := []struct {
+		name     string
+		maxPages int
+		want     int
+	}{
+		{"default limit", 0, defaultMaxPages - 1},
+		{"no limit", -1, longStoryPages - 1},
+	}
+
+	for _, limit := range limits {
+		t.Run(limit.name, func(t *testing.T) {
+			fetched = nil
+			parser := NewParser()
+			parser.PageFetcher = longStory
+			parser.MaxPages = limit.maxPages
+
+			first, _, _ := longStory(context.Background(), pageURL)
+			fetched = nil
+			if _, err := parser.Parse(first, pageURL); err != nil {
+				t.Fatal(err)
+			}
+			if len(fetched) != limit.want {
+				t.Errorf("want %d fetched pages, got %v", limit.want, fetched)
+			}
+		})
+	}
+}
@@ -1,19 +1,25 @@
 package readability
 
 import (
+	"bytes"
 	"io"
 	"math"
 	"strings"
 
 	"github.com/go-shiori/dom"
-	"github.com/go-shiori/go-readability/internal/re2go"
 	"golang.org/x/net/html"
 )
 
 // Check checks whether the input is readable without parsing the whole thing.
 func (ps *Parser) Check(input io.Reader) bool {
+	// Transcode input to UTF-8
+	content, _, err := decodeHTML(input, "")
+	if err != nil {
+		return false
+	}
+
 	// Parse input
-	doc, err := dom.Parse(input)
+	doc, err := dom.Parse(bytes.NewReader(content))
 	if err != nil {
 		return false
 	}
@@ -53,18 +59,18 @@ func (ps *Parser) CheckDocument(doc *html.Node) bool {
 	// This is a little cheeky, we use the accumulator 'score' to decide what
 	// to return from this callback.
 	score := float64(0)
-	return ps.someNode(nodes, func(node *html.Node) bool {
-		if !ps.isProbablyVisible(node) {
+	state := &parseState{Parser: ps, doc: doc}
+	return state.someNode(nodes, func(node *html.Node) bool {
+		if !state.isProbablyVisible(node) {
 			return false
 		}
 
 		matchString := dom.ClassName(node) + " " + dom.ID(node)
-		if re2go.IsUnlikelyCandidates(matchString) &&
-			!re2go.MaybeItsACandidate(matchString) {
+		if ps.isUnlikelyCandidate(matchString) && !ps.isMaybeCandidate(matchString) {
 			return false
 		}
 
-		if dom.TagName(node) == "p" && ps.hasAncestorTag(node, "li", -1, nil) {
+		if dom.TagName(node) == "p" && state.hasAncestorTag(node, "li", -1, nil) {
 			return false
 		}
 
@@ -1,6 +1,8 @@
 package readability
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	nurl "net/url"
@@ -12,15 +14,43 @@ import (
 	"golang.org/x/net/html"
 )
 
-// Parse parses a reader and find the main readable content.
+// Parse parses a reader and find the main readable content. The input is
+// transcoded to UTF-8 according to its byte order mark or <meta> charset
+// declaration.
 func (ps *Parser) Parse(input io.Reader, pageURL *nurl.URL) (Article, error) {
+	return ps.parseReader(context.Background(), input, "", pageURL)
+}
+
+// ParseContext is like Parse, but stops parsing and returns the error of ctx
+// as soon as ctx is canceled.
+func (ps *Parser) ParseContext(ctx context.Context, input io.Reader, pageURL *nurl.URL) (Article, error) {
+	return ps.parseReader(ctx, input, "", pageURL)
+}
+
+// ParseWithContentType is like Parse, but also takes into account the charset
+// from contentType, e.g. the Content-Type header of the HTTP response that the
+// input came from, when determining the encoding of the input.
+func (ps *Parser) ParseWithContentType(input io.Reader, contentType string, pageURL *nurl.URL) (Article, error) {
+	return ps.parseReader(context.Background(), input, contentType, pageURL)
+}
+
+// parseReader transcodes and parses input, then extracts its content.
+func (ps *Parser) parseReader(ctx context.Context, input io.Reader, contentType string, pageURL *nurl.URL) (Article, error) {
+	// Transcode input to UTF-8
+	content, encoding, err := decodeHTML(input, contentType)
+	if err != nil {
+		return Article{}, fmt.Errorf("failed to read input: %v", err)
+	}
+
 	// Parse input
-	doc, err := dom.Parse(input)
+	doc, err := dom.Parse(bytes.NewReader(content))
 	if err != nil {
 		return Article{}, fmt.Errorf("failed to parse input: %v", err)
 	}
 
-	return ps.ParseAndMutate(doc, pageURL)
+	article, err := ps.newParseState(doc, pageURL).parseAndMutate(ctx)
+	article.Encoding = encoding
+	return article, err
 }
 
 // ParseDocument parses the specified document and find the main readable content.
@@ -31,22 +61,38 @@ func (ps *Parser) ParseDocument(doc *html.Node, pageURL *nurl.URL) (Article, err
 
 // ParseAndMutate is like ParseDocument, but mutates doc during parsing.
 func (ps *Parser) ParseAndMutate(doc *html.Node, pageURL *nurl.URL) (Article, error) {
-	// Clone document to make sure the original kept untouched
-	ps.doc = doc
+	return ps.newParseState(doc, pageURL).parseAndMutate(context.Background())
+}
 
-	// Reset parser data
-	ps.articleTitle = ""
-	ps.articleByline = ""
-	ps.articleDir = ""
-	ps.articleSiteName = ""
-	ps.documentURI = pageURL
-	ps.attempts = []parseAttempt{}
-	ps.flags = flags{
-		stripUnlikelys:     true,
-		useWeightClasses:   true,
-		cleanConditionally: true,
+// newParseState returns the state of a new extraction of doc, which is
+// configured by ps.
+func (ps *Parser) newParseState(doc *html.Node, pageURL *nurl.URL) *parseState {
+	state := &parseState{
+		Parser:         ps,
+		doc:            doc,
+		documentURI:    pageURL,
+		noscriptImages: make(map[string]struct{}),
+		flags: flags{
+			stripUnlikelys:     true,
+			useWeightClasses:   true,
+			cleanConditionally: true,
+		},
 	}
+	if ps.CollectDiagnostics {
+		state.diagnostics = &Diagnostics{SelectedAttempt: -1}
+	}
+
+	state.siteRule = ps.SiteRules.match(pageURL)
+	if state.siteRule != nil && state.siteRule.SkipCleanConditionally {
+		state.flags.cleanConditionally = false
+	}
+	return state
+}
 
+// parseAndMutate extracts the content of the document of ps, mutating it.
+// It returns the error of ctx if it's canceled before the extraction is
+// finished.
+func (ps *parseState) parseAndMutate(ctx context.Context) (Article, error) {
 	// Avoid parsing too large documents, as per configuration option
 	if ps.MaxElemsToParse > 0 {
 		numTags := len(dom.GetElementsByTagName(ps.doc, "*"))
@@ -56,45 +102,136 @@ func (ps *Parser) ParseAndMutate(doc *html.Node, pageURL *nurl.URL) (Article, er
 	}
 
 	// Unwrap image from noscript
+	ps.phase = "prepDocument"
 	ps.unwrapNoscriptImages(ps.doc)
 
 	// Extract JSON-LD metadata before removing scripts
 	var jsonLd map[string]string
+	var linkedData *LinkedData
 	if !ps.DisableJSONLD {
-		jsonLd, _ = ps.getJSONLD()
+		ps.phase = "metadata"
+		jsonLd, linkedData = ps.getJSONLD()
+		ps.phase = "prepDocument"
 	}
 
 	// Remove script tags from the document.
 	ps.removeScripts(ps.doc)
 
+	// Apply the rule of the site, if any
+	var siteMetadata map[string]string
+	if ps.siteRule != nil {
+		siteMetadata = ps.siteRule.metadata(ps.doc)
+		ps.siteRule.prepare(ps.doc)
+		ps.collectKeptNodes(ps.doc)
+	}
+
 	// Prepares the HTML document
 	ps.prepDocument()
 
+	if err := ctx.Err(); err != nil {
+		return Article{}, err
+	}
+
 	// Fetch metadata
-	metadata := ps.getArticleMetadata(jsonLd)
+	ps.phase = "metadata"
+	var microdata, rdfa map[string]string
+	if !ps.DisableMicrodata {
+		microdata = ps.getItemMetadata(microdataSyntax)
+		rdfa = ps.getItemMetadata(rdfaSyntax)
+	}
+
+	metadata := ps.getArticleMetadata(jsonLd, microdata, rdfa)
+	authorLinks := ps.getAuthorLinks(ps.doc, false)
+	for key, value := range siteMetadata {
+		metadata[key] = value
+		ps.metadataSources[key] = "siterule"
+	}
 	ps.articleTitle = metadata["title"]
 	ps.articleByline = metadata["byline"]
 
+	// The sources of the dates are only kept if the dates can be parsed.
+	// If the page doesn't have a valid publication date in its metadata,
+	// look for it in the page before it's modified by the extraction.
+	publishedTime := ps.getDate(metadata, "publishedTime")
+	modifiedTime := ps.getDate(metadata, "modifiedTime")
+	if modifiedTime == nil {
+		delete(ps.metadataSources, "modifiedTime")
+	}
+	if publishedTime == nil {
+		delete(ps.metadataSources, "publishedTime")
+		var source string
+		if publishedTime, source = ps.discoverPublishedTime(metadata); publishedTime != nil {
+			ps.metadataSources["publishedTime"] = source
+		}
+	}
+
 	// Try to grab article content
 	finalHTMLContent := ""
 	finalTextContent := ""
-	articleContent := ps.grabArticle()
+	ps.phase = "grabArticle"
+	articleContent := ps.grabSiteContent()
+	if articleContent == nil {
+		var err error
+		if articleContent, err = ps.grabArticle(ctx); err != nil {
+			return Article{}, err
+		}
+	}
+
+	// The authors in microdata or RDFa are usually the byline that is
+	// found in the content, so they are only used if there wasn't any.
+	if ps.articleByline != "" && metadata["byline"] == "" {
+		ps.metadataSources["byline"] = "content"
+	}
+	if ps.articleByline == "" {
+		ps.articleByline = ps.pickMetadata("byline", []metadataSource{
+			{"microdata", microdata["byline"]},
+			{"rdfa", rdfa["byline"]},
+		})
+	}
 	var readableNode *html.Node
+	var images []ArticleImage
+	var links []ArticleLink
+	var outline []OutlineItem
 
 	if articleContent != nil {
+		ps.phase = "postProcessContent"
 		ps.postProcessContent(articleContent)
 
+		// Follow the links to the next pages of multi-page articles
+		var nextPages int
+		if ps.PageFetcher != nil {
+			nextPages = ps.appendNextPages(ctx, articleContent)
+		}
+
+		// Make an inventory of the images and links, now that the content
+		// is final
+		images = ps.collectImages(articleContent)
+		links = ps.collectLinks(articleContent)
+		outline = ps.getOutline(articleContent)
+		if metadata["image"] == "" {
+			metadata["image"] = ps.pickMetadata("image", []metadataSource{
+				{"content", leadImage(images)},
+			})
+		}
+
 		// If we haven't found an excerpt in the article's metadata,
 		// use the article's first paragraph as the excerpt. This is used
 		// for displaying a preview of the article's content.
 		if metadata["excerpt"] == "" {
 			paragraphs := dom.GetElementsByTagName(articleContent, "p")
 			if len(paragraphs) > 0 {
-				metadata["excerpt"] = strings.TrimSpace(dom.TextContent(paragraphs[0]))
+				metadata["excerpt"] = ps.pickMetadata("excerpt", []metadataSource{
+					{"content", strings.TrimSpace(dom.TextContent(paragraphs[0]))},
+				})
 			}
 		}
 
+		// The node of a multi-page article is the container of all its
+		// pages, so it has the same content as Content and TextContent
 		readableNode = dom.FirstElementChild(articleContent)
+		if nextPages > 0 {
+			readableNode = articleContent
+		}
 		finalHTMLContent = dom.InnerHTML(articleContent)
 		finalTextContent = dom.TextContent(articleContent)
 		finalTextContent = strings.TrimSpace(finalTextContent)
@@ -109,36 +246,59 @@ func (ps *Parser) ParseAndMutate(doc *html.Node, pageURL *nurl.URL) (Article, er
 	// Internet is dangerous and weird, and sometimes we will find
 	// metadata isn't encoded using a valid Utf-8, so here we check it.
 	var replacementTitle string
-	if pageURL != nil {
-		replacementTitle = pageURL.String()
+	if ps.documentURI != nil {
+		replacementTitle = ps.documentURI.String()
 	}
 
 	validTitle := strings.ToValidUTF8(ps.articleTitle, replacementTitle)
 	validByline := strings.ToValidUTF8(ps.articleByline, "")
+	authors := ps.getAuthors(linkedData, validByline, ps.bylineLinks, authorLinks)
 	validExcerpt := strings.ToValidUTF8(excerpt, "")
 
-	publishedTime := ps.getDate(metadata, "publishedTime")
-	modifiedTime := ps.getDate(metadata, "modifiedTime")
+	ps.phase = "metadata"
+	language, languageConfidence := ps.resolveLanguage(linkedData, finalTextContent)
+
+	var stats textStats
+	if articleContent != nil {
+		stats = getTextStats(articleContent)
+	}
+	readingTime := ps.readingTime(stats.words, language)
+	ps.phase = ""
 
 	return Article{
-		Title:         validTitle,
-		Byline:        validByline,
-		Node:          readableNode,
-		Content:       finalHTMLContent,
-		TextContent:   finalTextContent,
-		Length:        charCount(finalTextContent),
-		Excerpt:       validExcerpt,
-		SiteName:      metadata["siteName"],
-		Image:         metadata["image"],
-		Favicon:       metadata["favicon"],
-		Language:      ps.articleLang,
-		PublishedTime: publishedTime,
-		ModifiedTime:  modifiedTime,
+		Title:              validTitle,
+		Byline:             validByline,
+		Authors:            authors,
+		Node:               readableNode,
+		Content:            finalHTMLContent,
+		TextContent:        finalTextContent,
+		Length:             charCount(finalTextContent),
+		Excerpt:            validExcerpt,
+		SiteName:           metadata["siteName"],
+		Image:              metadata["image"],
+		Images:             images,
+		Favicon:            metadata["favicon"],
+		Links:              links,
+		Outline:            outline,
+		Language:           language,
+		LanguageConfidence: languageConfidence,
+		PublishedTime:      publishedTime,
+		ModifiedTime:       modifiedTime,
+		WordCount:          stats.words,
+		SentenceCount:      stats.sentences,
+		ParagraphCount:     stats.paragraphs,
+		ReadingTime:        readingTime,
+		ReadingTimeSeconds: int(readingTime / time.Second),
+		ReadabilityGrade:   stats.readabilityGrade(),
+		LinkedData:         linkedData,
+		Metadata:           ps.rawMetadata,
+		MetadataSources:    ps.metadataSources,
+		Diagnostics:        ps.diagnostics,
 	}, nil
 }
 
 // getDate tries to get a date from metadata, and parse it using a list of known formats.
-func (ps *Parser) getDate(metadata map[string]string, fieldName string) *time.Time {
+func (ps *parseState) getDate(metadata map[string]string, fieldName string) *time.Time {
 	dateStr, ok := metadata[fieldName]
 	if ok && len(dateStr) > 0 {
 		return ps.getParsedDate(dateStr)
@@ -148,7 +308,7 @@ func (ps *Parser) getDate(metadata map[string]string, fieldName string) *time.Ti
 
 // getParsedDate tries to parse a date string using a list of known formats.
 // If the date string can't be parsed, it will return nil.
-func (ps *Parser) getParsedDate(dateStr string) *time.Time {
+func (ps *parseState) getParsedDate(dateStr string) *time.Time {
 	d, err := dateparse.ParseAny(dateStr)
 	if err != nil {
 		ps.logf("failed to parse date \"%s\": %v\n", dateStr, err)
@@ -1,10 +1,11 @@
 package readability
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	shtml "html"
 	"log"
+	"log/slog"
 	"math"
 	nurl "net/url"
 	"regexp"
@@ -14,7 +15,6 @@ import (
 	"time"
 
 	"github.com/go-shiori/dom"
-	"github.com/go-shiori/go-readability/internal/re2go"
 	"golang.org/x/net/html"
 )
 
@@ -71,27 +71,94 @@ type flags struct {
 	cleanConditionally bool
 }
 
-// parseAttempt is container for the result of previous parse attempts.
+// parseAttempt is container for the result of a previous parse attempt.
 type parseAttempt struct {
 	articleContent *html.Node
 	textLength     int
+	index          int
 }
 
 // Article is the final readable content.
 type Article struct {
-	Title         string
-	Byline        string
-	Node          *html.Node
-	Content       string
-	TextContent   string
-	Length        int
-	Excerpt       string
-	SiteName      string
-	Image         string
-	Favicon       string
-	Language      string
-	PublishedTime *time.Time
-	ModifiedTime  *time.Time
+	Title  string `json:"title"`
+	Byline string `json:"byline"`
+	// Authors are the authors of the article, found in JSON-LD or in the
+	// byline, with the URL of their page if it's known.
+	Authors []Author `json:"authors,omitempty"`
+	// Node is the element that holds the readable content. For the
+	// articles stitched from several pages, it's the element that holds
+	// all the "readability-page-N" elements.
+	Node        *html.Node `json:"-"`
+	Content     string     `json:"content"`
+	TextContent string     `json:"textContent"`
+	Length      int        `json:"length"`
+	Excerpt     string     `json:"excerpt"`
+	SiteName    string     `json:"siteName"`
+	Image       string     `json:"image"`
+	// Images are the images in the article content. If the page doesn't
+	// declare an image in its metadata, the first large enough of them is
+	// used as Image.
+	Images  []ArticleImage `json:"images"`
+	Favicon string         `json:"favicon"`
+	// Links are the links in the article content.
+	Links []ArticleLink `json:"links"`
+	// Outline is the tree of the headings of the article content, e.g. to
+	// render a table of contents.
+	Outline  []OutlineItem `json:"outline,omitempty"`
+	Language string        `json:"language"`
+	// LanguageConfidence is how sure the parser is about Language, from 0
+	// to 1. It's 1 when the page declares its language, and lower when the
+	// language is guessed from the text of the article.
+	LanguageConfidence float64 `json:"languageConfidence,omitempty"`
+	// PublishedTime is the publication date of the article. If the page
+	// doesn't have it in its metadata, it's looked for in the page itself,
+	// e.g. in its <time> elements or in a dateline like "Posted on May 3,
+	// 2024", and the dates without a timezone are in the timezone of the
+	// other dates of the page.
+	PublishedTime *time.Time `json:"publishedTime"`
+	ModifiedTime  *time.Time `json:"modifiedTime"`
+	// WordCount is the number of words of the article. Every Chinese or
+	// Japanese character counts as a word.
+	WordCount int `json:"wordCount,omitempty"`
+	// SentenceCount and ParagraphCount are the number of sentences and of
+	// blocks of text, e.g. paragraphs or list items, of the article.
+	// Headings are counted as sentences, but not as paragraphs.
+	SentenceCount  int `json:"sentenceCount,omitempty"`
+	ParagraphCount int `json:"paragraphCount,omitempty"`
+	// ReadingTime is the estimated time needed to read the article, at the
+	// reading speed of its language. It's not encoded in JSON, where
+	// ReadingTimeSeconds is used instead.
+	ReadingTime time.Duration `json:"-"`
+	// ReadingTimeSeconds is ReadingTime in seconds.
+	ReadingTimeSeconds int `json:"readingTimeSeconds,omitempty"`
+	// ReadabilityGrade is the Flesch-Kincaid grade level of the article,
+	// i.e. the number of years of education needed to understand it. It's
+	// only computed for the languages written in the Latin script.
+	ReadabilityGrade float64 `json:"readabilityGrade,omitempty"`
+	// LinkedData is the Schema.org metadata of the article found in the
+	// JSON-LD of the page, if any.
+	LinkedData *LinkedData `json:"linkedData,omitempty"`
+	// Metadata is the content of the <meta> tags of the page that are used
+	// for the metadata, e.g. "og:title", "twitter:image", "dc:creator" or
+	// "article:published_time", by normalized name.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// MetadataSources tells where the metadata of the article comes from,
+	// by field: "title", "byline", "excerpt", "siteName", "image",
+	// "favicon", "language", "publishedTime" and "modifiedTime". A source
+	// is the name of a <meta> tag, "jsonld", "microdata", "rdfa",
+	// "siterule", an element like "<title>", "<link>" or "<html>", "header"
+	// for the Content-Language header, "content" when the value was found
+	// in the article content, "detected" when the language was guessed
+	// from the text, or "itemprop", "<time>", "dateline" or "url" when the
+	// publication date was found in the page.
+	MetadataSources map[string]string `json:"metadataSources,omitempty"`
+	// Diagnostics is the report of the content extraction. It's only set
+	// when Parser.CollectDiagnostics is enabled.
+	Diagnostics *Diagnostics `json:"diagnostics,omitempty"`
+	// Encoding is the name of the character encoding that the input was
+	// decoded from, e.g. "utf-8" or "shift_jis". It's empty when the parsed
+	// document was not read from raw bytes.
+	Encoding string `json:"encoding"`
 }
 
 // Parser is the parser that parses the page to get the readable content.
@@ -113,22 +180,121 @@ type Parser struct {
 	TagsToScore []string
 	// Debug determines if the log should be printed or not. Default: false.
 	Debug bool
+	// Logger receives the debug messages of the parser, with attributes
+	// for the phase, the parse attempt and the node being inspected. If
+	// it's nil, the messages are printed using the standard log package
+	// when Debug is enabled. Default: nil.
+	Logger *slog.Logger
 	// DisableJSONLD determines if metadata in JSON+LD will be extracted
 	// or not. Default: false.
 	DisableJSONLD bool
+	// DisableMicrodata determines if metadata in microdata and RDFa
+	// attributes will be extracted or not. Default: false.
+	DisableMicrodata bool
+	// ContentLanguage is the Content-Language header of the HTTP response
+	// that the document came from, if any. It's used as the language of the
+	// article when the document doesn't declare it. Default: ""
+	ContentLanguage string
+	// DisableLanguageDetection determines if the language of the article is
+	// guessed from its text when the page doesn't declare it. Default: false.
+	DisableLanguageDetection bool
+	// WordsPerMinute overrides the reading speeds used for the reading time
+	// of the article, by primary language subtag, e.g. {"en": 250}. The
+	// speed of Chinese and Japanese is in characters per minute. The speed
+	// of the "" key is used for the languages without a known speed.
+	// Default: nil
+	WordsPerMinute map[string]int
+	// AddHeadingIDs determines if the ids generated for the outline of the
+	// article are set on the headings of the content that don't have any,
+	// so the outline can link to them. Default: false.
+	AddHeadingIDs bool
 	// AllowedVideoRegex is a regular expression that matches video URLs that should be
 	// allowed to be included in the article content. If undefined, it will use default filter.
 	AllowedVideoRegex *regexp.Regexp
-
+	// UnlikelyCandidatesRegex replaces the pattern of class names and IDs
+	// of elements that are unlikely to be content, e.g. comments. If it's
+	// nil, DefaultUnlikelyCandidatesPattern is used. Default: nil
+	UnlikelyCandidatesRegex *regexp.Regexp
+	// MaybeCandidateRegex replaces the pattern of class names and IDs that
+	// save an unlikely candidate from being removed. If it's nil,
+	// DefaultMaybeCandidatePattern is used. Default: nil
+	MaybeCandidateRegex *regexp.Regexp
+	// PositiveClassRegex replaces the pattern of class names and IDs that
+	// increase the content score of an element. If it's nil,
+	// DefaultPositiveClassPattern is used. Default: nil
+	PositiveClassRegex *regexp.Regexp
+	// NegativeClassRegex replaces the pattern of class names and IDs that
+	// decrease the content score of an element. If it's nil,
+	// DefaultNegativeClassPattern is used. Default: nil
+	NegativeClassRegex *regexp.Regexp
+	// BylineRegex replaces the pattern of class names and IDs of elements
+	// that contain the byline. If it's nil, DefaultBylinePattern is used.
+	// Default: nil
+	BylineRegex *regexp.Regexp
+	// ExtraUnlikelyCandidates, ExtraPositiveClasses and ExtraNegativeClasses
+	// are words that are looked for in class names and IDs, ignoring case,
+	// in addition to the corresponding patterns above. Default: nil
+	ExtraUnlikelyCandidates []string
+	ExtraPositiveClasses    []string
+	ExtraNegativeClasses    []string
+	// PageFetcher enables stitching of multi-page articles. When set, the
+	// pages linked as the next page of the article are fetched using it and
+	// appended to the article content. Default: nil (disabled)
+	PageFetcher PageFetcher
+	// MaxPages is the max number of pages, including the first one, that
+	// are stitched together when PageFetcher is set. A negative value
+	// removes the limit, so the "next" links are followed as long as the
+	// fetched pages have new content. Default: 10, also used when it's 0
+	MaxPages int
+	// MaxAttempts is the max number of times the content extraction is
+	// attempted, each time with less strict rules, before the best attempt
+	// is used. Default: 0 (no limit)
+	MaxAttempts int
+	// MaxDuration is the time budget of the content extraction, which
+	// starts once the document is prepared and its metadata extracted.
+	// Once it's exceeded, the best attempt so far is used instead of trying
+	// again with less strict rules. It's checked after every attempt, so
+	// the first attempt always runs to completion. Default: 0 (no limit)
+	MaxDuration time.Duration
+	// CollectDiagnostics determines if a structured report of the content
+	// extraction is attached to the article. Default: false.
+	CollectDiagnostics bool
+	// SanitizePolicy restricts the elements, attributes and URLs in the
+	// article content to the ones it allows, so the content is safe to be
+	// embedded in a web page. Default: nil (disabled)
+	SanitizePolicy *SanitizePolicy
+	// SiteRules overrides the extraction for the sites that have a rule in
+	// it, looked up by the URL of the page. Default: nil
+	SiteRules *SiteRules
+}
+
+// parseState is the state of a single extraction, which is made for every
+// call of the parser. The extraction is done by its methods, so the Parser
+// itself is never modified and can be used by several goroutines.
+type parseState struct {
+	// Parser is the configuration of the extraction.
+	*Parser
+
+	// grabStart is when the content extraction started, for MaxDuration.
+	grabStart       time.Time
 	doc             *html.Node
 	documentURI     *nurl.URL
 	articleTitle    string
 	articleByline   string
+	bylineLinks     []Author
 	articleDir      string
 	articleSiteName string
 	articleLang     string
-	attempts        []parseAttempt
+	attempts        int
+	bestAttempt     *parseAttempt
 	flags           flags
+	diagnostics     *Diagnostics
+	phase           string
+	siteRule        *siteRule
+	keptNodes       map[*html.Node]struct{}
+	noscriptImages  map[string]struct{}
+	rawMetadata     map[string]string
+	metadataSources map[string]string
 }
 
 // NewParser returns new Parser which set up with default value.
@@ -141,12 +307,15 @@ func NewParser() Parser {
 		KeepClasses:       false,
 		TagsToScore:       []string{"section", "h2", "h3", "h4", "h5", "h6", "p", "td", "pre"},
 		Debug:             false,
+		MaxPages:          defaultMaxPages,
 	}
 }
 
 // postProcessContent runs any post-process modifications to article
 // content as necessary.
-func (ps *Parser) postProcessContent(articleContent *html.Node) {
+func (ps *parseState) postProcessContent(articleContent *html.Node) {
+	ps.collectKeptNodes(articleContent)
+
 	// Readability cannot open relative uris so we convert them to absolute uris.
 	ps.fixRelativeURIs(articleContent)
 
@@ -159,16 +328,21 @@ func (ps *Parser) postProcessContent(articleContent *html.Node) {
 
 	// Remove readability attributes.
 	ps.clearReadabilityAttr(articleContent)
+
+	// Remove everything that is not safe to embed.
+	if ps.SanitizePolicy != nil {
+		ps.sanitize(articleContent)
+	}
 }
 
 // removeNodes iterates over a NodeList, calls `filterFn` for each node
 // and removes node if function returned `true`. If function is not
 // passed, removes all the nodes in node list.
-func (ps *Parser) removeNodes(nodeList []*html.Node, filterFn func(*html.Node) bool) {
+func (ps *parseState) removeNodes(nodeList []*html.Node, filterFn func(*html.Node) bool) {
 	for i := len(nodeList) - 1; i >= 0; i-- {
 		node := nodeList[i]
 		parentNode := node.Parent
-		if parentNode != nil && (filterFn == nil || filterFn(node)) {
+		if parentNode != nil && !ps.isKept(node) && (filterFn == nil || filterFn(node)) {
 			parentNode.RemoveChild(node)
 		}
 	}
@@ -176,7 +350,7 @@ func (ps *Parser) removeNodes(nodeList []*html.Node, filterFn func(*html.Node) b
 
 // replaceNodeTags iterates over a NodeList, and calls setNodeTag for
 // each node.
-func (ps *Parser) replaceNodeTags(nodeList []*html.Node, newTagName string) {
+func (ps *parseState) replaceNodeTags(nodeList []*html.Node, newTagName string) {
 	for i := len(nodeList) - 1; i >= 0; i-- {
 		node := nodeList[i]
 		ps.setNodeTag(node, newTagName)
@@ -184,7 +358,7 @@ func (ps *Parser) replaceNodeTags(nodeList []*html.Node, newTagName string) {
 }
 
 // forEachNode iterates over a NodeList and runs fn on each node.
-func (ps *Parser) forEachNode(nodeList []*html.Node, fn func(*html.Node, int)) {
+func (ps *parseState) forEachNode(nodeList []*html.Node, fn func(*html.Node, int)) {
 	for i := 0; i < len(nodeList); i++ {
 		fn(nodeList[i], i)
 	}
@@ -192,7 +366,7 @@ func (ps *Parser) forEachNode(nodeList []*html.Node, fn func(*html.Node, int)) {
 
 // someNode iterates over a NodeList, return true if any of the
 // provided iterate function calls returns true, false otherwise.
-func (ps *Parser) someNode(nodeList []*html.Node, fn func(*html.Node) bool) bool {
+func (ps *parseState) someNode(nodeList []*html.Node, fn func(*html.Node) bool) bool {
 	for i := 0; i < len(nodeList); i++ {
 		if fn(nodeList[i]) {
 			return true
@@ -203,7 +377,7 @@ func (ps *Parser) someNode(nodeList []*html.Node, fn func(*html.Node) bool) bool
 
 // everyNode iterates over a NodeList, return true if all of the
 // provided iterate function calls returns true, false otherwise.
-func (ps *Parser) everyNode(nodeList []*html.Node, fn func(*html.Node) bool) bool {
+func (ps *parseState) everyNode(nodeList []*html.Node, fn func(*html.Node) bool) bool {
 	for i := 0; i < len(nodeList); i++ {
 		if !fn(nodeList[i]) {
 			return false
@@ -213,7 +387,7 @@ func (ps *Parser) everyNode(nodeList []*html.Node, fn func(*html.Node) bool) boo
 }
 
 // getAllNodesWithTag returns all nodes that has tag inside tagNames.
-func (ps *Parser) getAllNodesWithTag(node *html.Node, tagNames ...string) []*html.Node {
+func (ps *parseState) getAllNodesWithTag(node *html.Node, tagNames ...string) []*html.Node {
 	var result []*html.Node
 	for i := 0; i < len(tagNames); i++ {
 		result = append(result, dom.GetElementsByTagName(node, tagNames[i])...)
@@ -224,7 +398,7 @@ func (ps *Parser) getAllNodesWithTag(node *html.Node, tagNames ...string) []*htm
 // cleanClasses removes the class="" attribute from every element in the
 // given subtree, except those that match CLASSES_TO_PRESERVE and the
 // classesToPreserve array from the options object.
-func (ps *Parser) cleanClasses(node *html.Node) {
+func (ps *parseState) cleanClasses(node *html.Node) {
 	nodeClassName := dom.ClassName(node)
 	preservedClassName := []string{}
 	for _, class := range strings.Fields(nodeClassName) {
@@ -246,7 +420,7 @@ func (ps *Parser) cleanClasses(node *html.Node) {
 
 // fixRelativeURIs converts each <a> and <img> uri in the given element
 // to an absolute URI, ignoring #ref URIs.
-func (ps *Parser) fixRelativeURIs(articleContent *html.Node) {
+func (ps *parseState) fixRelativeURIs(articleContent *html.Node) {
 	links := ps.getAllNodesWithTag(articleContent, "a")
 	ps.forEachNode(links, func(link *html.Node, _ int) {
 		href := dom.GetAttribute(link, "href")
@@ -310,7 +484,7 @@ func (ps *Parser) fixRelativeURIs(articleContent *html.Node) {
 	})
 }
 
-func (ps *Parser) simplifyNestedElements(articleContent *html.Node) {
+func (ps *parseState) simplifyNestedElements(articleContent *html.Node) {
 	node := articleContent
 
 	for node != nil {
@@ -341,7 +515,7 @@ func (ps *Parser) simplifyNestedElements(articleContent *html.Node) {
 }
 
 // getArticleTitle attempts to get the article title.
-func (ps *Parser) getArticleTitle() string {
+func (ps *parseState) getArticleTitle() string {
 	doc := ps.doc
 	curTitle := ""
 	origTitle := ""
@@ -413,7 +587,7 @@ func (ps *Parser) getArticleTitle() string {
 // prepDocument prepares the HTML document for readability to scrape it.
 // This includes things like stripping javascript, CSS, and handling
 // terrible markup.
-func (ps *Parser) prepDocument() {
+func (ps *parseState) prepDocument() {
 	doc := ps.doc
 
 	// ADDITIONAL, not exist in readability.js:
@@ -433,7 +607,7 @@ func (ps *Parser) prepDocument() {
 // nextNode finds the next element, starting from the given node, and
 // ignoring whitespace in between. If the given node is an element, the
 // same node is returned.
-func (ps *Parser) nextNode(node *html.Node) *html.Node {
+func (ps *parseState) nextNode(node *html.Node) *html.Node {
 	next := node
 	for next != nil && next.Type != html.ElementNode && !hasTextContent(next) {
 		next = next.NextSibling
@@ -449,7 +623,7 @@ func (ps *Parser) nextNode(node *html.Node) *html.Node {
 // will become:
 //
 //	<div>foo<br>bar<p>abc</p></div>
-func (ps *Parser) replaceBrs(elem *html.Node) {
+func (ps *parseState) replaceBrs(elem *html.Node) {
 	ps.forEachNode(ps.getAllNodesWithTag(elem, "br"), func(br *html.Node, _ int) {
 		next := br.NextSibling
 
@@ -511,7 +685,7 @@ func (ps *Parser) replaceBrs(elem *html.Node) {
 }
 
 // setNodeTag changes tag of the node to newTagName.
-func (ps *Parser) setNodeTag(node *html.Node, newTagName string) {
+func (ps *parseState) setNodeTag(node *html.Node, newTagName string) {
 	if node.Type == html.ElementNode {
 		node.Data = newTagName
 	}
@@ -519,7 +693,9 @@ func (ps *Parser) setNodeTag(node *html.Node, newTagName string) {
 
 // prepArticle prepares the article node for display. Clean out any
 // inline styles, iframes, forms, strip extraneous <p> tags, etc.
-func (ps *Parser) prepArticle(articleContent *html.Node) {
+func (ps *parseState) prepArticle(articleContent *html.Node) {
+	ps.collectKeptNodes(articleContent)
+
 	ps.cleanStyles(articleContent)
 
 	// Check for data tables before we continue, to avoid removing
@@ -628,7 +804,7 @@ func (ps *Parser) prepArticle(articleContent *html.Node) {
 
 // initializeNode initializes a node with the readability score.
 // Also checks the className/id for special names to add to its score.
-func (ps *Parser) initializeNode(node *html.Node) {
+func (ps *parseState) initializeNode(node *html.Node) {
 	contentScore := float64(ps.getClassWeight(node))
 	switch dom.TagName(node) {
 	case "div":
@@ -645,7 +821,11 @@ func (ps *Parser) initializeNode(node *html.Node) {
 }
 
 // removeAndGetNext remove node and returns its next node.
-func (ps *Parser) removeAndGetNext(node *html.Node) *html.Node {
+func (ps *parseState) removeAndGetNext(node *html.Node) *html.Node {
+	if ps.isKept(node) {
+		return ps.getNextNode(node, false)
+	}
+
 	nextNode := ps.getNextNode(node, true)
 	if node.Parent != nil {
 		node.Parent.RemoveChild(node)
@@ -659,7 +839,7 @@ func (ps *Parser) removeAndGetNext(node *html.Node) *html.Node {
 // next node over. Calling this in a loop will traverse the DOM
 // depth-first.
 // In Readability.js, ignoreSelfAndKids default to false.
-func (ps *Parser) getNextNode(node *html.Node, ignoreSelfAndKids bool) *html.Node {
+func (ps *parseState) getNextNode(node *html.Node, ignoreSelfAndKids bool) *html.Node {
 	// First check for kids if those aren't being ignored
 	if firstChild := dom.FirstElementChild(node); !ignoreSelfAndKids && firstChild != nil {
 		return firstChild
@@ -690,7 +870,7 @@ func (ps *Parser) getNextNode(node *html.Node, ignoreSelfAndKids bool) *html.Nod
 // textSimilarity compares second text to first one. 1 = same text, 0 = completely different text.
 // The way it works: it splits both texts into words and then finds words that are unique in
 // second text the result is given by the lower length of unique parts.
-func (ps *Parser) textSimilarity(textA, textB string) float64 {
+func (ps *parseState) textSimilarity(textA, textB string) float64 {
 	tokensA := rxTokenize.Split(strings.ToLower(textA), -1)
 	tokensA = strFilter(tokensA, func(s string) bool { return s != "" })
 	mapTokensA := sliceToMap(tokensA...)
@@ -710,15 +890,15 @@ func (ps *Parser) textSimilarity(textA, textB string) float64 {
 }
 
 // isValidByline determines if a node is used as byline.
-func (ps *Parser) isValidByline(node *html.Node, matchString string) bool {
+func (ps *parseState) isValidByline(node *html.Node, matchString string) bool {
 	rel := dom.GetAttribute(node, "rel")
 	itemprop := dom.GetAttribute(node, "itemprop")
-	return rel == "author" || strings.Contains(itemprop, "author") || re2go.IsByline(matchString)
+	return rel == "author" || strings.Contains(itemprop, "author") || ps.isBylineClass(matchString)
 }
 
 // getNodeAncestors gets the node's direct parent and grandparents.
 // In Readability.js, maxDepth default to 0.
-func (ps *Parser) getNodeAncestors(node *html.Node, maxDepth int) []*html.Node {
+func (ps *parseState) getNodeAncestors(node *html.Node, maxDepth int) []*html.Node {
 	i := 0
 	var ancestors []*html.Node
 
@@ -736,11 +916,26 @@ func (ps *Parser) getNodeAncestors(node *html.Node, maxDepth int) []*html.Node {
 // grabArticle uses a variety of metrics (content score, classname,
 // element types), find the content that is most likely to be the
 // stuff a user wants to read. Then return it wrapped up in a div.
-func (ps *Parser) grabArticle() *html.Node {
+// It returns the error of ctx if it's canceled in the meantime.
+func (ps *parseState) grabArticle(ctx context.Context) (*html.Node, error) {
 	ps.log("**** GRAB ARTICLE ****")
+	ps.grabStart = time.Now()
+
+	// The attempts are made on the document itself. If it may be needed
+	// again, i.e. for another attempt or to find the next pages, a
+	// snapshot is taken to put it back the way it was.
+	var snapshot *docSnapshot
+	if ps.PageFetcher != nil || !ps.budgetExhausted() {
+		snapshot = newDocSnapshot(ps.doc)
+	}
 
 	for {
-		doc := dom.Clone(ps.doc, true)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		ps.startAttemptReport()
+		doc := ps.doc
 
 		var page *html.Node
 		if nodes := dom.GetElementsByTagName(doc, "body"); len(nodes) > 0 {
@@ -750,7 +945,7 @@ func (ps *Parser) grabArticle() *html.Node {
 		// We can't grab an article if we don't have a page!
 		if page == nil {
 			ps.log("no body found in document, abort")
-			return nil
+			return nil, nil
 		}
 
 		// First, node prepping. Trash nodes that look cruddy (like ones
@@ -770,7 +965,7 @@ func (ps *Parser) grabArticle() *html.Node {
 			}
 
 			if !ps.isProbablyVisible(node) {
-				ps.logf("removing hidden node: %q\n", matchString)
+				ps.logNodef(node, "removing hidden node: %q\n", matchString)
 				node = ps.removeAndGetNext(node)
 				continue
 			}
@@ -793,6 +988,7 @@ func (ps *Parser) grabArticle() *html.Node {
 					itemprop := dom.GetAttribute(next, "itemprop")
 					if strings.Contains(itemprop, "name") {
 						ps.articleByline = ps.getInnerText(next, false)
+						ps.bylineLinks = ps.getAuthorLinks(node, true)
 						node = ps.removeAndGetNext(node)
 						continue grabLoop
 					}
@@ -804,13 +1000,14 @@ func (ps *Parser) grabArticle() *html.Node {
 				// tests and the bylines end up different.
 				if nChar := charCount(bylineText); nChar > 0 && nChar < 100 {
 					ps.articleByline = normalizeWhitespace(bylineText)
+					ps.bylineLinks = ps.getAuthorLinks(node, true)
 					node = ps.removeAndGetNext(node)
 					continue
 				}
 			}
 
 			if shouldRemoveTitleHeader && ps.headerDuplicatesTitle(node) {
-				ps.logf("removing header: %q duplicate of %q\n",
+				ps.logNodef(node, "removing header: %q duplicate of %q\n",
 					ps.getInnerText(node, true), normalizeWhitespace(ps.articleTitle))
 				shouldRemoveTitleHeader = false
 				node = ps.removeAndGetNext(node)
@@ -820,19 +1017,19 @@ func (ps *Parser) grabArticle() *html.Node {
 			// Remove unlikely candidates
 			nodeTagName := dom.TagName(node)
 			if ps.flags.stripUnlikelys {
-				if re2go.IsUnlikelyCandidates(matchString) &&
-					!re2go.MaybeItsACandidate(matchString) &&
+				if ps.isUnlikelyCandidate(matchString) &&
+					!ps.isMaybeCandidate(matchString) &&
 					!ps.hasAncestorTag(node, "table", 3, nil) &&
 					!ps.hasAncestorTag(node, "code", 3, nil) &&
 					nodeTagName != "body" && nodeTagName != "a" {
-					ps.logf("removing unlikely candidate: %q\n", matchString)
+					ps.logNodef(node, "removing unlikely candidate: %q\n", matchString)
 					node = ps.removeAndGetNext(node)
 					continue
 				}
 
 				role := dom.GetAttribute(node, "role")
 				if _, include := unlikelyRoles[role]; include {
-					ps.logf("removing content with role %q: %q\n", role, matchString)
+					ps.logNodef(node, "removing content with role %q: %q\n", role, matchString)
 					node = ps.removeAndGetNext(node)
 					continue
 				}
@@ -987,11 +1184,20 @@ func (ps *Parser) grabArticle() *html.Node {
 		// Scale the final candidates score based on link density. Good
 		// content should have a relatively small link density (5% or
 		// less) and be mostly unaffected by this operation.
+		var linkDensities map[*html.Node]float64
+		if ps.diagnostics != nil {
+			linkDensities = make(map[*html.Node]float64)
+		}
+
 		for i := 0; i < len(candidates); i++ {
 			candidate := candidates[i]
-			candidateScore := ps.getContentScore(candidate) * (1 - ps.getLinkDensity(candidate))
-			ps.logf("candidate %q with score: %f\n", inspectNode(candidate), candidateScore)
+			linkDensity := ps.getLinkDensity(candidate)
+			candidateScore := ps.getContentScore(candidate) * (1 - linkDensity)
+			ps.logNodef(candidate, "candidate %q with score: %f\n", inspectNode(candidate), candidateScore)
 			ps.setContentScore(candidate, candidateScore)
+			if linkDensities != nil {
+				linkDensities[candidate] = linkDensity
+			}
 		}
 
 		// After we've calculated scores, sort through all of the possible
@@ -1006,6 +1212,7 @@ func (ps *Parser) grabArticle() *html.Node {
 		} else {
 			topCandidates = candidates
 		}
+		ps.reportCandidates(topCandidates, linkDensities)
 
 		var topCandidate, parentOfTopCandidate *html.Node
 		neededToCreateTopCandidate := false
@@ -1023,7 +1230,7 @@ func (ps *Parser) grabArticle() *html.Node {
 			// Move everything (not just elements, also text nodes etc.)
 			// into the container so we even include text directly in the body:
 			for page.FirstChild != nil {
-				ps.logf("moving child out: %q\n", inspectNode(page.FirstChild))
+				ps.logNodef(page.FirstChild, "moving child out: %q\n", inspectNode(page.FirstChild))
 				dom.AppendChild(topCandidate, page.FirstChild)
 			}
 
@@ -1174,9 +1381,15 @@ func (ps *Parser) grabArticle() *html.Node {
 			}
 		}
 
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		// So we have all of the content that we need. Now we clean
 		// it up for presentation.
+		ps.phase = "prepArticle"
 		ps.prepArticle(articleContent)
+		ps.phase = "grabArticle"
 
 		if neededToCreateTopCandidate {
 			// We already created a fake div thing, and there wouldn't
@@ -1215,212 +1428,106 @@ func (ps *Parser) grabArticle() *html.Node {
 		// the sieve approach gives us a higher likelihood of
 		// finding the -right- content.
 		textLength, _ := countCharsAndCommas(articleContent)
+		attempt := parseAttempt{
+			articleContent: articleContent,
+			textLength:     textLength,
+			index:          ps.attempts,
+		}
+
+		report := ps.currentAttemptReport()
+		if report != nil {
+			report.TextLength = textLength
+		}
+
 		if textLength < ps.CharThresholds {
 			parseSuccessful = false
 
-			if ps.flags.stripUnlikelys {
+			droppedFlag := ""
+			if ps.budgetExhausted() {
+				ps.log("extraction budget exhausted, using best attempt")
+				attempt = ps.betterAttempt(attempt)
+				articleContent = attempt.articleContent
+				parseSuccessful = attempt.textLength > 0
+			} else if ps.flags.stripUnlikelys {
 				ps.flags.stripUnlikelys = false
-				ps.attempts = append(ps.attempts, parseAttempt{
-					articleContent: articleContent,
-					textLength:     textLength,
-				})
+				droppedFlag = "stripUnlikelys"
 			} else if ps.flags.useWeightClasses {
 				ps.flags.useWeightClasses = false
-				ps.attempts = append(ps.attempts, parseAttempt{
-					articleContent: articleContent,
-					textLength:     textLength,
-				})
+				droppedFlag = "useWeightClasses"
 			} else if ps.flags.cleanConditionally {
 				ps.flags.cleanConditionally = false
-				ps.attempts = append(ps.attempts, parseAttempt{
-					articleContent: articleContent,
-					textLength:     textLength,
-				})
+				droppedFlag = "cleanConditionally"
 			} else {
-				ps.attempts = append(ps.attempts, parseAttempt{
-					articleContent: articleContent,
-					textLength:     textLength,
-				})
-
 				// No luck after removing flags, just return the
 				// longest text we found during the different loops *
-				sort.Slice(ps.attempts, func(i, j int) bool {
-					return ps.attempts[i].textLength > ps.attempts[j].textLength
-				})
-
-				// But first check if we actually have something
-				if ps.attempts[0].textLength == 0 {
-					return nil
-				}
-
-				articleContent = ps.attempts[0].articleContent
-				parseSuccessful = true
-			}
-		}
-
-		if parseSuccessful {
-			return articleContent
-		}
-	}
-}
-
-// getJSONLD try to extract metadata from JSON-LD object.
-// For now, only Schema.org objects of type Article or its subtypes are supported.
-func (ps *Parser) getJSONLD() (map[string]string, error) {
-	var metadata map[string]string
-
-	scripts := dom.QuerySelectorAll(ps.doc, `script[type="application/ld+json"]`)
-	ps.forEachNode(scripts, func(jsonLdElement *html.Node, _ int) {
-		if metadata != nil {
-			return
-		}
-
-		// Strip CDATA markers if present
-		content := rxCDATA.ReplaceAllString(dom.TextContent(jsonLdElement), "")
-
-		// Decode JSON
-		var parsedContent interface{}
-		err := json.Unmarshal([]byte(content), &parsedContent)
-		if err != nil {
-			ps.logf("error while decoding json: %v", err)
-			return
-		}
-
-		var parsed map[string]interface{}
-		switch pc := parsedContent.(type) {
-		case []interface{}:
-			for _, item := range pc {
-				if parsedItem, ok := item.(map[string]interface{}); ok {
-					if parsedType, ok := parsedItem["@type"].(string); ok && rxJsonLdArticleTypes.MatchString(parsedType) {
-						parsed = parsedItem
-						break
-					}
-				}
+				attempt = ps.betterAttempt(attempt)
+				articleContent = attempt.articleContent
+				parseSuccessful = attempt.textLength > 0
 			}
-		case map[string]interface{}:
-			parsed = pc
-		}
-		if parsed == nil {
-			ps.log("unrecognized JSON-LD structure")
-			return
-		}
 
-		// Check context
-		switch ct := parsed["@context"].(type) {
-		case string:
-			if !rxSchemaOrg.MatchString(ct) {
-				return
+			// Give up if none of the attempts found any text
+			if droppedFlag == "" && !parseSuccessful {
+				return nil, nil
 			}
-		case map[string]interface{}:
-			if vocabStr, ok := ct["@vocab"].(string); !ok || !rxSchemaOrg.MatchString(vocabStr) {
-				return
-			}
-		default:
-			return
-		}
 
-		// If parsed doesn't have any @type, find it in its graph list
-		if _, typeExist := parsed["@type"]; !typeExist {
-			graphList, isArray := parsed["@graph"].([]interface{})
-			if !isArray {
-				return
+			if report != nil {
+				report.DroppedFlag = droppedFlag
 			}
 
-			for _, graph := range graphList {
-				objGraph, isObj := graph.(map[string]interface{})
-				if !isObj {
-					continue
-				}
-
-				strType, isString := objGraph["@type"].(string)
-				if isString && rxJsonLdArticleTypes.MatchString(strType) {
-					parsed = objGraph
-					break
+			// Only the content of the best attempt is kept. It's made
+			// of nodes of the document, so it has to be copied before
+			// the document is restored for the next attempt.
+			if droppedFlag != "" {
+				if ps.bestAttempt == nil || attempt.textLength > ps.bestAttempt.textLength {
+					attempt.articleContent = dom.Clone(attempt.articleContent, true)
+					ps.bestAttempt = &attempt
 				}
+				ps.attempts++
+				snapshot.restore()
 			}
 		}
 
-		// Once again, make sure parsed has valid @type
-		strType, isString := parsed["@type"].(string)
-		if !isString || !rxJsonLdArticleTypes.MatchString(strType) {
-			return
-		}
-
-		// Initiate metadata
-		metadata = make(map[string]string)
-
-		// Title
-		name, nameIsString := parsed["name"].(string)
-		headline, headlineIsString := parsed["headline"].(string)
-
-		if nameIsString && headlineIsString && name != headline {
-			// We have both name and headline element in the JSON-LD. They should both be the same
-			// but some websites like aktualne.cz put their own name into "name" and the article
-			// title to "headline" which confuses Readability. So we try to check if either "name"
-			// or "headline" closely matches the html title, and if so, use that one. If not, then
-			// we use "name" by default.
-			title := ps.getArticleTitle()
-			nameMatches := ps.textSimilarity(name, title) > 0.75
-			headlineMatches := ps.textSimilarity(headline, title) > 0.75
-
-			if headlineMatches && !nameMatches {
-				metadata["title"] = headline
-			} else {
-				metadata["title"] = name
-			}
-		} else if name, isString := parsed["name"].(string); isString {
-			metadata["title"] = strings.TrimSpace(name)
-		} else if headline, isString := parsed["headline"].(string); isString {
-			metadata["title"] = strings.TrimSpace(headline)
+		if parseSuccessful && ps.diagnostics != nil {
+			ps.diagnostics.SelectedAttempt = attempt.index
 		}
 
-		// Author
-		switch val := parsed["author"].(type) {
-		case map[string]interface{}:
-			if name, isString := val["name"].(string); isString {
-				metadata["byline"] = strings.TrimSpace(name)
-			}
-
-		case []interface{}:
-			var authors []string
-			for _, author := range val {
-				objAuthor, isObj := author.(map[string]interface{})
-				if !isObj {
-					continue
-				}
-
-				if name, isString := objAuthor["name"].(string); isString {
-					authors = append(authors, strings.TrimSpace(name))
+		if parseSuccessful {
+			// The document is still needed to find the next pages.
+			if ps.PageFetcher != nil {
+				if attempt.index == ps.attempts {
+					articleContent = dom.Clone(articleContent, true)
 				}
+				snapshot.restore()
 			}
-			metadata["byline"] = strings.Join(authors, ", ")
-		}
-
-		// Description
-		if description, isString := parsed["description"].(string); isString {
-			metadata["excerpt"] = strings.TrimSpace(description)
-		}
-
-		// Publisher
-		if objPublisher, isObj := parsed["publisher"].(map[string]interface{}); isObj {
-			if name, isString := objPublisher["name"].(string); isString {
-				metadata["siteName"] = strings.TrimSpace(name)
-			}
-		}
-
-		// DatePublished
-		if datePublished, isString := parsed["datePublished"].(string); isString {
-			metadata["datePublished"] = datePublished
+			return articleContent, nil
 		}
+	}
+}
 
-	})
+// betterAttempt returns the parse attempt with the longest text, between
+// attempt and the best of the previous attempts.
+func (ps *parseState) betterAttempt(attempt parseAttempt) parseAttempt {
+	if ps.bestAttempt != nil && ps.bestAttempt.textLength >= attempt.textLength {
+		return *ps.bestAttempt
+	}
+	return attempt
+}
 
-	return metadata, nil
+// budgetExhausted returns true if the content extraction shouldn't be
+// attempted again, because of MaxAttempts or MaxDuration.
+func (ps *parseState) budgetExhausted() bool {
+	if ps.MaxAttempts > 0 && ps.attempts+1 >= ps.MaxAttempts {
+		return true
+	}
+	return ps.MaxDuration > 0 && time.Since(ps.grabStart) >= ps.MaxDuration
 }
 
 // getArticleMetadata attempts to get excerpt and byline
-// metadata for the article.
-func (ps *Parser) getArticleMetadata(jsonLd map[string]string) map[string]string {
+// metadata for the article. The metadata of the <meta> tags comes after
+// the JSON-LD ones, and before the microdata and RDFa ones, which are often
+// partial or describe only a part of the page. The values of the <meta>
+// tags and the source of each field are kept in the parse state.
+func (ps *parseState) getArticleMetadata(jsonLd, microdata, rdfa map[string]string) map[string]string {
 	values := make(map[string]string)
 	metaElements := dom.GetElementsByTagName(ps.doc, "meta")
 
@@ -1432,6 +1539,15 @@ func (ps *Parser) getArticleMetadata(jsonLd map[string]string) map[string]string
 		if content == "" {
 			return
 		}
+
+		// The language of the page
+		if strings.EqualFold(dom.GetAttribute(element, "http-equiv"), "content-language") {
+			values["content-language"] = strings.TrimSpace(content)
+		}
+		if strings.EqualFold(strings.TrimSpace(elementProperty), "og:locale") {
+			values["og:locale"] = strings.TrimSpace(content)
+		}
+
 		matches := []string{}
 		name := ""
 
@@ -1457,76 +1573,104 @@ func (ps *Parser) getArticleMetadata(jsonLd map[string]string) map[string]string
 		}
 	})
 
+	ps.rawMetadata = values
+	ps.metadataSources = make(map[string]string)
+
 	// get title
-	metadataTitle := strOr(
-		jsonLd["title"],
-		values["dc:title"],
-		values["dcterm:title"],
-		values["og:title"],
-		values["weibo:article:title"],
-		values["weibo:webpage:title"],
-		values["title"],
-		values["twitter:title"],
-		values["parsely-title"],
-	)
+	metadataTitle := ps.pickMetadata("title", []metadataSource{
+		{"jsonld", jsonLd["title"]},
+		{"dc:title", values["dc:title"]},
+		{"dcterm:title", values["dcterm:title"]},
+		{"og:title", values["og:title"]},
+		{"weibo:article:title", values["weibo:article:title"]},
+		{"weibo:webpage:title", values["weibo:webpage:title"]},
+		{"title", values["title"]},
+		{"twitter:title", values["twitter:title"]},
+		{"parsely-title", values["parsely-title"]},
+	})
 
+	// The headline of microdata and RDFa may be the one of a single block
+	// of the page, so it comes after the <meta> tags. It's still preferred
+	// to the <title>, which usually has the name of the site too.
 	if metadataTitle == "" {
-		metadataTitle = ps.getArticleTitle()
+		metadataTitle = ps.pickMetadata("title", []metadataSource{
+			{"microdata", microdata["title"]},
+			{"rdfa", rdfa["title"]},
+			{"<title>", ps.getArticleTitle()},
+		})
 	}
 
 	// get author
-	metadataByline := strOr(
-		jsonLd["byline"],
-		values["dc:creator"],
-		values["dcterm:creator"],
-		values["author"],
-		values["parsely-author"],
-	)
+	metadataByline := ps.pickMetadata("byline", []metadataSource{
+		{"jsonld", jsonLd["byline"]},
+		{"dc:creator", values["dc:creator"]},
+		{"dcterm:creator", values["dcterm:creator"]},
+		{"author", values["author"]},
+		{"parsely-author", values["parsely-author"]},
+	})
 
 	if metadataByline == "" && !isValidURL(values["article:author"]) {
-		metadataByline = values["article:author"]
+		metadataByline = ps.pickMetadata("byline", []metadataSource{
+			{"article:author", values["article:author"]},
+		})
 	}
 
 	// get description
-	metadataExcerpt := strOr(
-		jsonLd["excerpt"],
-		values["dc:description"],
-		values["dcterm:description"],
-		values["og:description"],
-		values["weibo:article:description"],
-		values["weibo:webpage:description"],
-		values["description"],
-		values["twitter:description"])
+	metadataExcerpt := ps.pickMetadata("excerpt", []metadataSource{
+		{"jsonld", jsonLd["excerpt"]},
+		{"dc:description", values["dc:description"]},
+		{"dcterm:description", values["dcterm:description"]},
+		{"og:description", values["og:description"]},
+		{"weibo:article:description", values["weibo:article:description"]},
+		{"weibo:webpage:description", values["weibo:webpage:description"]},
+		{"description", values["description"]},
+		{"twitter:description", values["twitter:description"]},
+	})
 
 	// get site name
-	metadataSiteName := strOr(jsonLd["siteName"], values["og:site_name"])
+	metadataSiteName := ps.pickMetadata("siteName", []metadataSource{
+		{"jsonld", jsonLd["siteName"]},
+		{"og:site_name", values["og:site_name"]},
+		{"microdata", microdata["siteName"]},
+		{"rdfa", rdfa["siteName"]},
+	})
 
 	// get image thumbnail
-	metadataImage := strOr(
-		values["og:image"],
-		values["image"],
-		values["twitter:image"])
+	metadataImage := ps.pickMetadata("image", []metadataSource{
+		{"og:image", values["og:image"]},
+		{"image", values["image"]},
+		{"twitter:image", values["twitter:image"]},
+		{"jsonld", jsonLd["image"]},
+		{"microdata", microdata["image"]},
+		{"rdfa", rdfa["image"]},
+	})
 
 	// get favicon
-	metadataFavicon := ps.getArticleFavicon()
+	metadataFavicon := ps.pickMetadata("favicon", []metadataSource{
+		{"<link>", ps.getArticleFavicon()},
+	})
 
 	// get published date
-	metadataPublishedTime := strOr(
-		jsonLd["datePublished"],
-		values["article:published_time"],
-		values["dcterms.available"],
-		values["dcterms.created"],
-		values["dcterms.issued"],
-		values["weibo:article:create_at"],
-		values["parsely-pub-date"],
-	)
+	metadataPublishedTime := ps.pickMetadata("publishedTime", []metadataSource{
+		{"jsonld", jsonLd["datePublished"]},
+		{"article:published_time", values["article:published_time"]},
+		{"dcterms.available", values["dcterms.available"]},
+		{"dcterms.created", values["dcterms.created"]},
+		{"dcterms.issued", values["dcterms.issued"]},
+		{"weibo:article:create_at", values["weibo:article:create_at"]},
+		{"parsely-pub-date", values["parsely-pub-date"]},
+		{"microdata", microdata["datePublished"]},
+		{"rdfa", rdfa["datePublished"]},
+	})
 
 	// get modified date
-	metadataModifiedTime := strOr(
-		jsonLd["dateModified"],
-		values["article:modified_time"],
-		values["dcterms.modified"],
-	)
+	metadataModifiedTime := ps.pickMetadata("modifiedTime", []metadataSource{
+		{"jsonld", jsonLd["dateModified"]},
+		{"article:modified_time", values["article:modified_time"]},
+		{"dcterms.modified", values["dcterms.modified"]},
+		{"microdata", microdata["dateModified"]},
+		{"rdfa", rdfa["dateModified"]},
+	})
 
 	// in many sites the meta value is escaped with HTML entities,
 	// so here we need to unescape it
@@ -1549,9 +1693,29 @@ func (ps *Parser) getArticleMetadata(jsonLd map[string]string) map[string]string
 	}
 }
 
+// metadataSource is a candidate value of a metadata field, with the name of
+// the place it was found in: a <meta> tag, "jsonld", "microdata", "rdfa" or
+// an element of the document like "<title>".
+type metadataSource struct {
+	name  string
+	value string
+}
+
+// pickMetadata returns the first candidate value of field that isn't
+// empty, and records where it comes from.
+func (ps *parseState) pickMetadata(field string, candidates []metadataSource) string {
+	for _, candidate := range candidates {
+		if candidate.value != "" {
+			ps.metadataSources[field] = candidate.name
+			return candidate.value
+		}
+	}
+	return ""
+}
+
 // isSingleImage checks if node is image, or if node contains exactly
 // only one image whether as a direct child or as its descendants.
-func (ps *Parser) isSingleImage(node *html.Node) bool {
+func (ps *parseState) isSingleImage(node *html.Node) bool {
 	if dom.TagName(node) == "img" {
 		return true
 	}
@@ -1568,7 +1732,7 @@ func (ps *Parser) isSingleImage(node *html.Node) bool {
 // and which contain only one <img> element. Replace the first image with
 // the image from inside the <noscript> tag, and remove the <noscript> tag.
 // This improves the quality of the images we use on some sites (e.g. Medium).
-func (ps *Parser) unwrapNoscriptImages(doc *html.Node) {
+func (ps *parseState) unwrapNoscriptImages(doc *html.Node) {
 	// Find img without source or attributes that might contains image, and
 	// remove it. This is done to prevent a placeholder img is replaced by
 	// img from noscript in next step.
@@ -1638,6 +1802,7 @@ func (ps *Parser) unwrapNoscriptImages(doc *html.Node) {
 				}
 			}
 
+			ps.recordNoscriptImage(newImg)
 			dom.ReplaceChild(noscript.Parent, dom.FirstElementChild(tmpBody), prevElement)
 		} else {
 			// Replace <noscript> with the image that was in it.
@@ -1648,13 +1813,14 @@ func (ps *Parser) unwrapNoscriptImages(doc *html.Node) {
 			if dom.GetAttribute(img, "width") == "1" && dom.GetAttribute(img, "height") == "1" {
 				return
 			}
+			ps.recordNoscriptImage(img)
 			dom.ReplaceChild(noscript.Parent, img, noscript)
 		}
 	})
 }
 
 // removeScripts removes script tags from the document.
-func (ps *Parser) removeScripts(doc *html.Node) {
+func (ps *parseState) removeScripts(doc *html.Node) {
 	ps.removeNodes(ps.getAllNodesWithTag(doc, "script", "noscript"), nil)
 }
 
@@ -1662,7 +1828,7 @@ func (ps *Parser) removeScripts(doc *html.Node) {
 // and a single element with given tag. Returns false if the DIV node
 // contains non-empty text nodes or if it contains no element with
 // given tag or more than 1 element.
-func (ps *Parser) hasSingleTagInsideElement(element *html.Node, tag string) bool {
+func (ps *parseState) hasSingleTagInsideElement(element *html.Node, tag string) bool {
 	// There should be exactly 1 element child with given tag
 	if childs := dom.Children(element); len(childs) != 1 || dom.TagName(childs[0]) != tag {
 		return false
@@ -1676,7 +1842,7 @@ func (ps *Parser) hasSingleTagInsideElement(element *html.Node, tag string) bool
 
 // isElementWithoutContent determines if node is empty
 // or only filled with <br> and <hr>.
-func (ps *Parser) isElementWithoutContent(node *html.Node) bool {
+func (ps *parseState) isElementWithoutContent(node *html.Node) bool {
 	if node.Type != html.ElementNode {
 		return false
 	}
@@ -1696,7 +1862,7 @@ func (ps *Parser) isElementWithoutContent(node *html.Node) bool {
 
 // hasChildBlockElement determines whether element has any children
 // block level elements.
-func (ps *Parser) hasChildBlockElement(element *html.Node) bool {
+func (ps *parseState) hasChildBlockElement(element *html.Node) bool {
 	return ps.someNode(dom.ChildNodes(element), func(node *html.Node) bool {
 		_, exist := divToPElems[dom.TagName(node)]
 		return exist || ps.hasChildBlockElement(node)
@@ -1704,7 +1870,7 @@ func (ps *Parser) hasChildBlockElement(element *html.Node) bool {
 }
 
 // isPhrasingContent determines if a node qualifies as phrasing content.
-func (ps *Parser) isPhrasingContent(node *html.Node) bool {
+func (ps *parseState) isPhrasingContent(node *html.Node) bool {
 	nodeTagName := dom.TagName(node)
 	return node.Type == html.TextNode || indexOf(phrasingElems, nodeTagName) != -1 ||
 		((nodeTagName == "a" || nodeTagName == "del" || nodeTagName == "ins") &&
@@ -1712,14 +1878,14 @@ func (ps *Parser) isPhrasingContent(node *html.Node) bool {
 }
 
 // isWhitespace determines if a node only used as whitespace.
-func (ps *Parser) isWhitespace(node *html.Node) bool {
+func (ps *parseState) isWhitespace(node *html.Node) bool {
 	return (node.Type == html.TextNode && !hasTextContent(node)) ||
 		(node.Type == html.ElementNode && dom.TagName(node) == "br")
 }
 
 // getInnerText gets the inner text of a node. This also strips out any excess
 // whitespace to be found. In Readability.js, normalizeSpaces defaults to true.
-func (ps *Parser) getInnerText(node *html.Node, normalizeSpaces bool) string {
+func (ps *parseState) getInnerText(node *html.Node, normalizeSpaces bool) string {
 	textContent := dom.TextContent(node)
 	if normalizeSpaces {
 		return normalizeWhitespace(textContent)
@@ -1728,7 +1894,7 @@ func (ps *Parser) getInnerText(node *html.Node, normalizeSpaces bool) string {
 }
 
 // cleanStyles removes the style attribute on every node and under.
-func (ps *Parser) cleanStyles(node *html.Node) {
+func (ps *parseState) cleanStyles(node *html.Node) {
 	nodeTagName := dom.TagName(node)
 	if node == nil || nodeTagName == "svg" {
 		return
@@ -1752,7 +1918,7 @@ func (ps *Parser) cleanStyles(node *html.Node) {
 // getLinkDensity gets the density of links as a percentage of the
 // content. This is the amount of text that is inside a link divided
 // by the total text in the node.
-func (ps *Parser) getLinkDensity(element *html.Node) float64 {
+func (ps *parseState) getLinkDensity(element *html.Node) float64 {
 	chars := &charCounter{}
 	var linkCharsWeighted float64
 
@@ -1799,7 +1965,7 @@ func getLinkDensityCoefficient(a *html.Node) float64 {
 
 // getClassWeight gets an elements class/id weight. Uses regular
 // expressions to tell if this element looks good or bad.
-func (ps *Parser) getClassWeight(node *html.Node) int {
+func (ps *parseState) getClassWeight(node *html.Node) int {
 	if !ps.flags.useWeightClasses {
 		return 0
 	}
@@ -1808,22 +1974,22 @@ func (ps *Parser) getClassWeight(node *html.Node) int {
 
 	// Look for a special classname
 	if nodeClassName := dom.ClassName(node); nodeClassName != "" {
-		if re2go.IsNegativeClass(nodeClassName) {
+		if ps.isNegativeClass(nodeClassName) {
 			weight -= 25
 		}
 
-		if re2go.IsPositiveClass(nodeClassName) {
+		if ps.isPositiveClass(nodeClassName) {
 			weight += 25
 		}
 	}
 
 	// Look for a special ID
 	if nodeID := dom.ID(node); nodeID != "" {
-		if re2go.IsNegativeClass(nodeID) {
+		if ps.isNegativeClass(nodeID) {
 			weight -= 25
 		}
 
-		if re2go.IsPositiveClass(nodeID) {
+		if ps.isPositiveClass(nodeID) {
 			weight += 25
 		}
 	}
@@ -1833,7 +1999,7 @@ func (ps *Parser) getClassWeight(node *html.Node) int {
 
 // clean cleans a node of all elements of type "tag".
 // (Unless it's a youtube/vimeo video. People love movies.)
-func (ps *Parser) clean(node *html.Node, tag string) {
+func (ps *parseState) clean(node *html.Node, tag string) {
 	ps.removeNodes(dom.GetElementsByTagName(node, tag), func(element *html.Node) bool {
 		return !ps.isVideoEmbed(element)
 	})
@@ -1841,7 +2007,7 @@ func (ps *Parser) clean(node *html.Node, tag string) {
 
 // isVideoEmbed returns true if the element looks like a video embed with respect to the
 // AllowedVideoRegex property.
-func (ps *Parser) isVideoEmbed(embed *html.Node) bool {
+func (ps *parseState) isVideoEmbed(embed *html.Node) bool {
 	if embed.Data != "object" && embed.Data != "embed" && embed.Data != "iframe" {
 		return false
 	}
@@ -1868,7 +2034,7 @@ func (ps *Parser) isVideoEmbed(embed *html.Node) bool {
 // hasAncestorTag checks if a given node has one of its ancestor tag
 // name matching the provided one. In Readability.js, default value
 // for maxDepth is 3.
-func (ps *Parser) hasAncestorTag(node *html.Node, tag string, maxDepth int, filterFn func(*html.Node) bool) bool {
+func (ps *parseState) hasAncestorTag(node *html.Node, tag string, maxDepth int, filterFn func(*html.Node) bool) bool {
 	depth := 0
 	for node.Parent != nil {
 		if maxDepth > 0 && depth > maxDepth {
@@ -1886,7 +2052,7 @@ func (ps *Parser) hasAncestorTag(node *html.Node, tag string, maxDepth int, filt
 }
 
 // getRowAndColumnCount returns how many rows and columns this table has.
-func (ps *Parser) getRowAndColumnCount(table *html.Node) (int, int) {
+func (ps *parseState) getRowAndColumnCount(table *html.Node) (int, int) {
 	rows := 0
 	columns := 0
 	trs := dom.GetElementsByTagName(table, "tr")
@@ -1921,7 +2087,7 @@ func (ps *Parser) getRowAndColumnCount(table *html.Node) (int, int) {
 // markDataTables looks for 'data' (as opposed to 'layout') tables
 // and mark it, which similar as used in Firefox:
 // https://searchfox.org/mozilla-central/rev/f82d5c549f046cb64ce5602bfd894b7ae807c8f8/accessible/generic/TableAccessible.cpp#19
-func (ps *Parser) markDataTables(root *html.Node) {
+func (ps *parseState) markDataTables(root *html.Node) {
 	tables := dom.GetElementsByTagName(root, "table")
 	for i := 0; i < len(tables); i++ {
 		table := tables[i]
@@ -1992,7 +2158,7 @@ func (ps *Parser) markDataTables(root *html.Node) {
 
 // fixLazyImages convert images and figures that have properties like data-src into
 // images that can be loaded without JS.
-func (ps *Parser) fixLazyImages(root *html.Node) {
+func (ps *parseState) fixLazyImages(root *html.Node) {
 	imageNodes := ps.getAllNodesWithTag(root, "img", "picture", "figure")
 	ps.forEachNode(imageNodes, func(elem *html.Node, _ int) {
 		src := dom.GetAttribute(elem, "src")
@@ -2077,7 +2243,7 @@ func (ps *Parser) fixLazyImages(root *html.Node) {
 // cleanConditionally cleans an element of all tags of type "tag" if
 // they look fishy. "Fishy" is an algorithm based on content length,
 // classnames, link density, number of images & embeds, etc.
-func (ps *Parser) cleanConditionally(element *html.Node, tag string) {
+func (ps *parseState) cleanConditionally(element *html.Node, tag string) {
 	if !ps.flags.cleanConditionally {
 		return
 	}
@@ -2105,6 +2271,7 @@ func (ps *Parser) cleanConditionally(element *html.Node, tag string) {
 		var contentScore int
 		weight := ps.getClassWeight(node)
 		if weight+contentScore < 0 {
+			ps.reportRemoval(node, "negative class weight")
 			return true
 		}
 
@@ -2214,6 +2381,7 @@ func (ps *Parser) cleanConditionally(element *html.Node, tag string) {
 				// normalizeWhitespace.
 				innerTextSingle = strings.TrimSpace(innerTextSingle)
 				if rxAdWords.MatchString(innerTextSingle) || rxLoadingWords.MatchString(innerTextSingle) {
+					ps.reportRemoval(node, "ad or loading placeholder")
 					return true
 				}
 			}
@@ -2231,48 +2399,74 @@ func (ps *Parser) cleanConditionally(element *html.Node, tag string) {
 			// the number of paragraphs.
 			const liCountOffset = -100
 
+			// The reason is only formatted when it's logged or reported,
+			// since most nodes are kept.
+			explain := ps.explainsRemovals()
 			haveToRemove := false
+			var reason string
 			if imgCount > 1 && float64(pCount)/float64(imgCount) < 0.5 && !ps.hasAncestorTag(node, "figure", 3, nil) {
-				ps.logf("bad p to img ratio (img=%d, p=%d)", pCount, imgCount)
 				haveToRemove = true
+				if explain {
+					reason = fmt.Sprintf("bad p to img ratio (img=%d, p=%d)", imgCount, pCount)
+				}
 			} else if !isList && (liCount+liCountOffset) > pCount {
-				ps.logf("too many li's outside of a list (li=%d%+d > p=%d)", liCount, liCountOffset, pCount)
 				haveToRemove = true
+				if explain {
+					reason = fmt.Sprintf("too many li's outside of a list (li=%d%+d > p=%d)", liCount, liCountOffset, pCount)
+				}
 			} else if float64(inputCount) > math.Floor(float64(pCount)/3) {
-				ps.logf("too many inputs per p (input=%d, p=%d)", inputCount, pCount)
 				haveToRemove = true
+				if explain {
+					reason = fmt.Sprintf("too many inputs per p (input=%d, p=%d)", inputCount, pCount)
+				}
 			} else if !isList && headingDensity < 0.9 && chars.Total < 25 && (imgCount == 0 || imgCount > 2) && linkDensity > 0 && !ps.hasAncestorTag(node, "figure", 3, nil) {
-				ps.logf("suspiciously short (headingDensity=%.2f, img=%d, linkDensity=%.2f)", headingDensity, imgCount, linkDensity)
 				haveToRemove = true
+				if explain {
+					reason = fmt.Sprintf("suspiciously short (headingDensity=%.2f, img=%d, linkDensity=%.2f)", headingDensity, imgCount, linkDensity)
+				}
 			} else if !isList && weight < 25 && linkDensity > 0.2 {
-				ps.logf("low weight and a little linky (linkDensity=%.2f)", linkDensity)
 				haveToRemove = true
+				if explain {
+					reason = fmt.Sprintf("low weight and a little linky (linkDensity=%.2f)", linkDensity)
+				}
 			} else if weight >= 25 && linkDensity > 0.5 {
-				ps.logf("high weight and mostly links (linkDensity=%.2f)", linkDensity)
 				haveToRemove = true
+				if explain {
+					reason = fmt.Sprintf("high weight and mostly links (linkDensity=%.2f)", linkDensity)
+				}
 			} else if (embedCount == 1 && chars.Total < 75) || embedCount > 1 {
-				ps.logf("suspicious embed (embedCount=%d, contentLength=%d)", embedCount, chars.Total)
 				haveToRemove = true
+				if explain {
+					reason = fmt.Sprintf("suspicious embed (embedCount=%d, contentLength=%d)", embedCount, chars.Total)
+				}
 			} else if imgCount == 0 && textDensity == 0 {
-				ps.log("no useful content (img=0, textDensity=0.0)")
 				haveToRemove = true
+				reason = "no useful content (img=0, textDensity=0.0)"
+			}
+			if haveToRemove && explain {
+				ps.logNodef(node, "%s", reason)
 			}
 
 			// Allow simple lists of images to remain in pages
 			if isList && haveToRemove {
+				keepList := true
 				for _, child := range dom.Children(node) {
 					// Don't filter in lists with li's that contain more than one child
 					if len(dom.Children(child)) > 1 {
-						return haveToRemove
+						keepList = false
+						break
 					}
 				}
 
 				// Only allow the list to remain if every li contains an image
-				if imgCount == liCount {
+				if keepList && imgCount == liCount {
 					return false
 				}
 			}
 
+			if haveToRemove {
+				ps.reportRemoval(node, reason)
+			}
 			return haveToRemove
 		}
 
@@ -2282,7 +2476,7 @@ func (ps *Parser) cleanConditionally(element *html.Node, tag string) {
 
 // cleanMatchedNodes cleans out elements whose id/class
 // combinations match specific string.
-func (ps *Parser) cleanMatchedNodes(e *html.Node, filter func(*html.Node, string) bool) {
+func (ps *parseState) cleanMatchedNodes(e *html.Node, filter func(*html.Node, string) bool) {
 	endOfSearchMarkerNode := ps.getNextNode(e, true)
 	next := ps.getNextNode(e, false)
 	for next != nil && next != endOfSearchMarkerNode {
@@ -2295,12 +2489,12 @@ func (ps *Parser) cleanMatchedNodes(e *html.Node, filter func(*html.Node, string
 }
 
 // cleanHeaders cleans out spurious headers from an Element.
-func (ps *Parser) cleanHeaders(e *html.Node) {
+func (ps *parseState) cleanHeaders(e *html.Node) {
 	headingNodes := ps.getAllNodesWithTag(e, "h1", "h2")
 	ps.removeNodes(headingNodes, func(node *html.Node) bool {
 		// Removing header with low class weight
 		if ps.getClassWeight(node) < 0 {
-			ps.logf("removing header with low class weight: %q\n", inspectNode(node))
+			ps.logNodef(node, "removing header with low class weight: %q\n", inspectNode(node))
 			return true
 		}
 		return false
@@ -2309,18 +2503,18 @@ func (ps *Parser) cleanHeaders(e *html.Node) {
 
 // headerDuplicateTitle check if this node is an H1 or H2 element whose content
 // is mostly the same as the article title.
-func (ps *Parser) headerDuplicatesTitle(node *html.Node) bool {
+func (ps *parseState) headerDuplicatesTitle(node *html.Node) bool {
 	if tag := dom.TagName(node); tag != "h1" && tag != "h2" {
 		return false
 	}
 
 	heading := ps.getInnerText(node, false)
-	ps.logf("evaluating similarity of header: %q and %q\n", heading, ps.articleTitle)
+	ps.logNodef(node, "evaluating similarity of header: %q and %q\n", heading, ps.articleTitle)
 	return ps.textSimilarity(ps.articleTitle, heading) > 0.75
 }
 
 // isProbablyVisible determines if a node is visible.
-func (ps *Parser) isProbablyVisible(node *html.Node) bool {
+func (ps *parseState) isProbablyVisible(node *html.Node) bool {
 	nodeStyle := dom.GetAttribute(node, "style")
 	nodeAriaHidden := dom.GetAttribute(node, "aria-hidden")
 	className := dom.GetAttribute(node, "class")
@@ -2345,7 +2539,7 @@ func (ps *Parser) isProbablyVisible(node *html.Node) bool {
 // that used in article. It will only pick favicon in PNG
 // format, so small favicon that uses ico file won't be picked.
 // Using algorithm by philippe_b.
-func (ps *Parser) getArticleFavicon() string {
+func (ps *parseState) getArticleFavicon() string {
 	favicon := ""
 	faviconSize := -1
 	linkElements := dom.GetElementsByTagName(ps.doc, "link")
@@ -2385,7 +2579,7 @@ func (ps *Parser) getArticleFavicon() string {
 }
 
 // removeComments find all comments in document then remove it.
-func (ps *Parser) removeComments(doc *html.Node) {
+func (ps *parseState) removeComments(doc *html.Node) {
 	// Find all comments
 	var comments []*html.Node
 	var finder func(*html.Node)
@@ -2422,7 +2616,7 @@ func (ps *Parser) removeComments(doc *html.Node) {
 // HTML nodes. Hence why these methods exists.
 
 // setReadabilityDataTable marks whether a Node is data table or not.
-func (ps *Parser) setReadabilityDataTable(node *html.Node, isDataTable bool) {
+func (ps *parseState) setReadabilityDataTable(node *html.Node, isDataTable bool) {
 	if isDataTable {
 		dom.SetAttribute(node, "data-readability-table", "true")
 	} else {
@@ -2431,22 +2625,22 @@ func (ps *Parser) setReadabilityDataTable(node *html.Node, isDataTable bool) {
 }
 
 // isReadabilityDataTable determines if node is data table.
-func (ps *Parser) isReadabilityDataTable(node *html.Node) bool {
+func (ps *parseState) isReadabilityDataTable(node *html.Node) bool {
 	return dom.HasAttribute(node, "data-readability-table")
 }
 
 // setContentScore sets the readability score for a node.
-func (ps *Parser) setContentScore(node *html.Node, score float64) {
+func (ps *parseState) setContentScore(node *html.Node, score float64) {
 	dom.SetAttribute(node, "data-readability-score", fmt.Sprintf("%.4f", score))
 }
 
 // hasContentScore checks if node has readability score.
-func (ps *Parser) hasContentScore(node *html.Node) bool {
+func (ps *parseState) hasContentScore(node *html.Node) bool {
 	return dom.HasAttribute(node, "data-readability-score")
 }
 
 // getContentScore gets the readability score of a node.
-func (ps *Parser) getContentScore(node *html.Node) float64 {
+func (ps *parseState) getContentScore(node *html.Node) float64 {
 	strScore := dom.GetAttribute(node, "data-readability-score")
 	strScore = strings.TrimSpace(strScore)
 	if strScore == "" {
@@ -2459,25 +2653,64 @@ func (ps *Parser) getContentScore(node *html.Node) float64 {
 
 // clearReadabilityAttr removes Readability attribute that
 // created by this package. Used in `postProcessContent`.
-func (ps *Parser) clearReadabilityAttr(node *html.Node) {
+func (ps *parseState) clearReadabilityAttr(node *html.Node) {
 	dom.RemoveAttribute(node, "data-readability-score")
 	dom.RemoveAttribute(node, "data-readability-table")
+	dom.RemoveAttribute(node, "data-readability-keep")
 
 	for child := dom.FirstElementChild(node); child != nil; child = dom.NextElementSibling(child) {
 		ps.clearReadabilityAttr(child)
 	}
 }
 
-func (ps *Parser) log(args ...interface{}) {
-	if ps.Debug {
-		log.Println(args...)
+func (ps *parseState) log(args ...interface{}) {
+	if ps.debugEnabled() {
+		ps.writeLog(nil, fmt.Sprintln(args...))
+	}
+}
+
+func (ps *parseState) logf(format string, args ...interface{}) {
+	if ps.debugEnabled() {
+		ps.writeLog(nil, fmt.Sprintf(format, args...))
+	}
+}
+
+// logNodef is like logf, but also attaches the node being inspected to the
+// log record.
+func (ps *parseState) logNodef(node *html.Node, format string, args ...interface{}) {
+	if ps.debugEnabled() {
+		ps.writeLog(node, fmt.Sprintf(format, args...))
+	}
+}
+
+// debugEnabled reports whether debug messages should be logged.
+func (ps *Parser) debugEnabled() bool {
+	if ps.Logger != nil {
+		return ps.Logger.Enabled(context.Background(), slog.LevelDebug)
 	}
+	return ps.Debug
 }
 
-func (ps *Parser) logf(format string, args ...interface{}) {
-	if ps.Debug {
-		log.Printf(format, args...)
+// writeLog writes msg either to Logger, with attributes for the current
+// phase, parse attempt and node, or to the standard logger.
+func (ps *parseState) writeLog(node *html.Node, msg string) {
+	msg = strings.TrimSuffix(msg, "\n")
+	if ps.Logger == nil {
+		log.Println(msg)
+		return
+	}
+
+	attrs := make([]slog.Attr, 0, 3)
+	if ps.phase != "" {
+		attrs = append(attrs, slog.String("phase", ps.phase))
+		if ps.phase == "grabArticle" || ps.phase == "prepArticle" {
+			attrs = append(attrs, slog.Int("attempt", ps.attempts+1))
+		}
+	}
+	if node != nil {
+		attrs = append(attrs, slog.Any("node", inspectNode(node)))
 	}
+	ps.Logger.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
 }
 
 // inspectNode wraps a HTML node to use with printf-style functions.
@@ -2507,8 +2740,6 @@ func (n *inspectedNode) String() string {
 // var (
 // 	rxExtraneous   = regexp.MustCompile(`(?i)print|archive|comment|discuss|e[\-]?mail|share|reply|all|login|sign|single|utility`)
 // 	rxReplaceFonts = regexp.MustCompile(`(?i)<(/?)font[^>]*>`)
-// 	rxNextLink     = regexp.MustCompile(`(?i)(next|weiter|continue|>([^\|]|$)|»([^\|]|$))`)
-// 	rxPrevLink     = regexp.MustCompile(`(?i)(prev|earl|old|new|<|«)`)
 // )
 
 // // findNode iterates over a NodeList and return the first node that passes
@@ -2,12 +2,16 @@ package readability
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	fp "path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 	"unicode/utf8"
@@ -112,6 +116,53 @@ func Test_parser(t *testing.T) {
 	}
 }
 
+func Test_parser_concurrent(t *testing.T) {
+	testItems, err := os.ReadDir("test-pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A single parser is shared by all goroutines, run with -race to make
+	// sure it's not modified while parsing.
+	parser := NewParser()
+	parser.CollectDiagnostics = true
+
+	var wg sync.WaitGroup
+	for _, item := range testItems {
+		if !item.IsDir() {
+			continue
+		}
+
+		content, err := os.ReadFile(fp.Join("test-pages", item.Name(), "source.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+
+			article, err := parser.Parse(bytes.NewReader(content), fakeHostURL)
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+				return
+			}
+
+			newParser := NewParser()
+			want, err := newParser.Parse(bytes.NewReader(content), fakeHostURL)
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+				return
+			}
+
+			if article.Title != want.Title || article.Content != want.Content {
+				t.Errorf("%s: article is different from the one of a new parser", name)
+			}
+		}(item.Name())
+	}
+	wg.Wait()
+}
+
 func Test_countCharsAndCommas(t *testing.T) {
 	createElement := func(tagName string, children ...*html.Node) *html.Node {
 		node := &html.Node{
@@ -166,6 +217,104 @@ func Test_countCharsAndCommas(t *testing.T) {
 	}
 }
 
+func Test_Article_JSON(t *testing.T) {
+	published := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	article := Article{
+		Title:         "Title",
+		Node:          &html.Node{Type: html.ElementNode, Data: "div"},
+		Content:       "<p>Text</p>",
+		TextContent:   "Text",
+		Length:        4,
+		SiteName:      "Site",
+		PublishedTime: &published,
+	}
+
+	data, err := json.Marshal(article)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"title":         "Title",
+		"byline":        "",
+		"content":       "<p>Text</p>",
+		"textContent":   "Text",
+		"length":        float64(4),
+		"excerpt":       "",
+		"siteName":      "Site",
+		"image":         "",
+		"images":        nil,
+		"favicon":       "",
+		"links":         nil,
+		"language":      "",
+		"publishedTime": "2023-04-05T06:07:08Z",
+		"modifiedTime":  nil,
+		"encoding":      "",
+	}
+
+	if len(fields) != len(want) {
+		t.Errorf("want %d fields got %d: %s", len(want), len(fields), data)
+	}
+	for key, value := range want {
+		if got, exist := fields[key]; !exist || got != value {
+			t.Errorf("field %q: want %v got %v", key, value, got)
+		}
+	}
+}
+
+func Test_Article_JSON_roundTrip(t *testing.T) {
+	paragraph := "<p>" + strings.Repeat("The council met on Tuesday evening to discuss the new budget, which includes more money for schools. ", 4) + "</p>"
+	page := `<html lang="en"><head><title>Council Approves the Budget</title></head><body><article>` +
+		strings.Repeat(paragraph, 10) + `</article></body></html>`
+
+	parser := NewParser()
+	article, err := parser.Parse(strings.NewReader(page), fakeHostURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.PublishedTime != nil || article.ReadingTimeSeconds == 0 {
+		t.Fatalf("want no published time and a reading time, got %v and %d", article.PublishedTime, article.ReadingTimeSeconds)
+	}
+
+	data, err := json.Marshal(article)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if got, exist := fields["publishedTime"]; !exist || got != nil {
+		t.Errorf("publishedTime: want null got %v", got)
+	}
+	if got := fields["readingTimeSeconds"]; got != float64(article.ReadingTimeSeconds) {
+		t.Errorf("readingTimeSeconds: want %d got %v", article.ReadingTimeSeconds, got)
+	}
+
+	var decoded Article
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ReadingTimeSeconds != article.ReadingTimeSeconds || decoded.PublishedTime != nil {
+		t.Errorf("decoded article: want reading time %d and no published time, got %d and %v",
+			article.ReadingTimeSeconds, decoded.ReadingTimeSeconds, decoded.PublishedTime)
+	}
+
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, data) {
+		t.Errorf("decoded article is encoded differently\nwant: %s\ngot : %s", data, encoded)
+	}
+}
+
 func extractSourceFile(path string) (Article, bool, *html.Node, error) {
 	// Open source file
 	f, err := os.Open(path)
@@ -305,7 +454,7 @@ func compareArticleContent(result, expected *html.Node) error {
 		}
 
 		// Move to next node
-		ps := Parser{}
+		ps := parseState{Parser: &Parser{}}
 		resultNode = ps.getNextNode(resultNode, false)
 		expectedNode = ps.getNextNode(expectedNode, false)
 	}
@@ -391,7 +540,221 @@ func timesAreEqual(metadataTimeString string, parsedTime *time.Time) bool {
 		return false
 	}
 
-	ps := Parser{}
+	ps := parseState{Parser: &Parser{}}
 	metadataTime := ps.getParsedDate(metadataTimeString)
 	return metadataTime.Equal(*parsedTime)
 }
+
+func Test_ParseContext(t *testing.T) {
+	f, err := os.Open("test-pages/wikipedia-2/source.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	parser := NewParser()
+	if _, err := parser.ParseContext(ctx, f, fakeHostURL); !errors.Is(err, context.Canceled) {
+		t.Errorf("want context.Canceled got %v", err)
+	}
+}
+
+func Test_parserBudget(t *testing.T) {
+	// The first attempt only finds the short paragraph, because the long
+	// one is inside an unlikely candidate.
+	page := `<html><body>
+<p>This is a short paragraph that is found by the first attempt, but it's not long enough.</p>
+<div class="sidebar"><p>` + strings.Repeat("This is a long paragraph, that is only found once unlikely candidates are kept. ", 10) + `</p></div>
+</body></html>`
+
+	tests := []struct {
+		name     string
+		budget   func(*Parser)
+		attempts int
+		sidebar  bool
+	}{
+		{
+			name:     "no budget",
+			budget:   func(*Parser) {},
+			attempts: 2,
+			sidebar:  true,
+		},
+		{
+			name:     "max attempts",
+			budget:   func(ps *Parser) { ps.MaxAttempts = 1 },
+			attempts: 1,
+		},
+		{
+			name:     "max duration",
+			budget:   func(ps *Parser) { ps.MaxDuration = time.Nanosecond },
+			attempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser()
+			parser.CollectDiagnostics = true
+			tt.budget(&parser)
+
+			article, err := parser.Parse(strings.NewReader(page), fakeHostURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := len(article.Diagnostics.Attempts); got != tt.attempts {
+				t.Errorf("attempts, want %d got %d", tt.attempts, got)
+			}
+			if got := strings.Contains(article.TextContent, "long paragraph"); got != tt.sidebar {
+				t.Errorf("contains sidebar, want %v got %v", tt.sidebar, got)
+			}
+			if !strings.Contains(article.TextContent, "short paragraph") {
+				t.Errorf("article doesn't contain the short paragraph")
+			}
+		})
+	}
+}
+
+func Test_getArticleMetadata_sources(t *testing.T) {
+	page := `<html><head><title>Fallback Title - Example</title>
+<meta property="og:title" content="Library Opens Downtown">
+<meta property="og:site_name" content="Example News">
+<meta name="twitter:image" content="https://example.com/library.jpg">
+<meta property="article:author" content="https://example.com/authors/jane">
+<meta name="description" content="The new library opened on Monday.">
+<link rel="icon" href="/favicon.png" sizes="32x32">
+</head><body><article>
+<p class="byline">By Jane Doe</p>
+<div itemscope itemtype="https://schema.org/Article"><time itemprop="datePublished" datetime="2024-05-17">May 17</time></div>
+<p>The new library opened on Monday, with a collection of more than one hundred thousand books.</p>
+</article></body></html>`
+
+	parser := NewParser()
+	parser.CharThresholds = 0
+	article, err := parser.Parse(strings.NewReader(page), fakeHostURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantSources := map[string]string{
+		"title":         "og:title",
+		"byline":        "content",
+		"excerpt":       "description",
+		"siteName":      "og:site_name",
+		"image":         "twitter:image",
+		"favicon":       "<link>",
+		"language":      "detected",
+		"publishedTime": "microdata",
+	}
+	if !reflect.DeepEqual(article.MetadataSources, wantSources) {
+		t.Errorf("metadata sources\nwant: %v\ngot : %v", wantSources, article.MetadataSources)
+	}
+
+	wantMetadata := map[string]string{
+		"og:title":       "Library Opens Downtown",
+		"og:site_name":   "Example News",
+		"twitter:image":  "https://example.com/library.jpg",
+		"article:author": "https://example.com/authors/jane",
+		"description":    "The new library opened on Monday.",
+	}
+	if !reflect.DeepEqual(article.Metadata, wantMetadata) {
+		t.Errorf("metadata\nwant: %v\ngot : %v", wantMetadata, article.Metadata)
+	}
+}
+
+func Test_resolveLanguage(t *testing.T) {
+	const text = `<p>The council met on Tuesday evening to discuss the new budget, which
+includes more money for public schools, libraries and the repair of old bridges.
+Many residents came to the meeting because they wanted to know how the changes
+would affect their neighbourhood.</p>`
+
+	scenarios := []struct {
+		name            string
+		html            string
+		contentLanguage string
+		disable         bool
+		wantLanguage    string
+		wantSource      string
+	}{
+		{
+			name:         "html lang attribute",
+			html:         `<html lang="en-US"><head><meta http-equiv="content-language" content="fr"></head><body>` + text + `</body></html>`,
+			wantLanguage: "en-US",
+			wantSource:   "<html>",
+		},
+		{
+			name:         "html lang attribute is normalized",
+			html:         `<html lang="en_US, fr"><body>` + text + `</body></html>`,
+			wantLanguage: "en-US",
+			wantSource:   "<html>",
+		},
+		{
+			name:            "meta http-equiv before header",
+			html:            `<html><head><meta http-equiv="Content-Language" content="en-GB, fr"></head><body>` + text + `</body></html>`,
+			contentLanguage: "de",
+			wantLanguage:    "en-GB",
+			wantSource:      "content-language",
+		},
+		{
+			name:            "content-language header",
+			html:            `<html><head><meta property="og:locale" content="fr_FR"></head><body>` + text + `</body></html>`,
+			contentLanguage: "de-DE",
+			wantLanguage:    "de-DE",
+			wantSource:      "header",
+		},
+		{
+			name:         "og:locale",
+			html:         `<html><head><meta property="og:locale" content="en_US"></head><body>` + text + `</body></html>`,
+			wantLanguage: "en-US",
+			wantSource:   "og:locale",
+		},
+		{
+			name:         "json-ld inLanguage",
+			html:         `<html><head><script type="application/ld+json">{"@context": "https://schema.org", "@type": "NewsArticle", "headline": "Budget", "inLanguage": "en-AU"}</script></head><body>` + text + `</body></html>`,
+			wantLanguage: "en-AU",
+			wantSource:   "jsonld",
+		},
+		{
+			name:         "invalid declarations are ignored",
+			html:         `<html><head><meta property="og:locale" content="English"></head><body>` + text + `</body></html>`,
+			wantLanguage: "en",
+			wantSource:   "detected",
+		},
+		{
+			name:         "detection disabled",
+			html:         `<html><body>` + text + `</body></html>`,
+			disable:      true,
+			wantLanguage: "",
+			wantSource:   "",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			parser := NewParser()
+			parser.CharThresholds = 0
+			parser.ContentLanguage = scenario.contentLanguage
+			parser.DisableLanguageDetection = scenario.disable
+			article, err := parser.Parse(strings.NewReader(scenario.html), fakeHostURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if article.Language != scenario.wantLanguage {
+				t.Errorf("language: want %q got %q", scenario.wantLanguage, article.Language)
+			}
+			if source := article.MetadataSources["language"]; source != scenario.wantSource {
+				t.Errorf("source: want %q got %q", scenario.wantSource, source)
+			}
+
+			switch {
+			case scenario.wantSource == "detected" && (article.LanguageConfidence <= 0 || article.LanguageConfidence >= 1):
+				t.Errorf("confidence: want between 0 and 1 got %v", article.LanguageConfidence)
+			case scenario.wantSource != "detected" && scenario.wantSource != "" && article.LanguageConfidence != 1:
+				t.Errorf("confidence: want 1 got %v", article.LanguageConfidence)
+			}
+		})
+	}
+}
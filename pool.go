@@ -0,0 +1,126 @@
+package readability
+
+import (
+	"context"
+	"fmt"
+	"io"
+	nurl "net/url"
+	"runtime"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// Input is a document to be extracted by a Pool. Either Reader or Document
+// must be set.
+type Input struct {
+	// ID is an arbitrary identifier that is copied to the Result, to match
+	// the results with their inputs.
+	ID string
+	// Reader is the raw HTML of the document. It's read until EOF, but not
+	// closed by the pool.
+	Reader io.Reader
+	// ContentType is used to detect the charset of Reader, like in
+	// Parser.ParseWithContentType.
+	ContentType string
+	// ContentLanguage overrides Parser.ContentLanguage for this document,
+	// if it's not empty.
+	ContentLanguage string
+	// Document is an already parsed document. It's mutated by the
+	// extraction, like in Parser.ParseAndMutate.
+	Document *html.Node
+	// URL is the URL of the document.
+	URL *nurl.URL
+}
+
+// Result is the outcome of the extraction of an Input.
+type Result struct {
+	ID      string
+	Article Article
+	Err     error
+}
+
+// Pool extracts many documents concurrently, using a limited number of
+// workers that share the same parser configuration.
+type Pool struct {
+	parser  Parser
+	workers int
+}
+
+// NewPool returns a pool that extracts documents using the configuration of
+// parser, with at most workers documents extracted at the same time. If
+// workers is not positive, GOMAXPROCS workers are used.
+//
+// The parser is copied, so changing it afterwards doesn't affect the pool.
+// However, the values it points to, e.g. SiteRules or SanitizePolicy, must
+// not be modified while the pool is in use.
+func NewPool(parser Parser, workers int) *Pool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &Pool{parser: parser, workers: workers}
+}
+
+// Extract extracts every document received from inputs, and sends the
+// results to the returned channel, not necessarily in the same order. The
+// channel is closed once inputs is closed and all of its documents have
+// been extracted, or once ctx is canceled. The caller must keep receiving
+// from the channel until it's closed.
+func (p *Pool) Extract(ctx context.Context, inputs <-chan Input) <-chan Result {
+	results := make(chan Result)
+
+	var wg sync.WaitGroup
+	wg.Add(p.workers)
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				var input Input
+				var ok bool
+				select {
+				case <-ctx.Done():
+					return
+				case input, ok = <-inputs:
+					if !ok {
+						return
+					}
+				}
+
+				result := p.extract(ctx, input)
+				select {
+				case <-ctx.Done():
+					return
+				case results <- result:
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// extract extracts a single input. The extraction is aborted if ctx is
+// canceled.
+func (p *Pool) extract(ctx context.Context, input Input) Result {
+	result := Result{ID: input.ID}
+	run := p.parser
+	if input.ContentLanguage != "" {
+		run.ContentLanguage = input.ContentLanguage
+	}
+
+	switch {
+	case input.Document != nil:
+		result.Article, result.Err = run.newParseState(input.Document, input.URL).parseAndMutate(ctx)
+	case input.Reader != nil:
+		result.Article, result.Err = run.parseReader(ctx, input.Reader, input.ContentType, input.URL)
+	default:
+		result.Err = fmt.Errorf("input %q has no document", input.ID)
+	}
+
+	return result
+}
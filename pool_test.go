@@ -0,0 +1,88 @@
+package readability
+
+import (
+	"bytes"
+	"context"
+	"os"
+	fp "path/filepath"
+	"testing"
+)
+
+func Test_Pool(t *testing.T) {
+	testItems, err := os.ReadDir("test-pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Extract every test page sequentially, to compare with the pool.
+	want := make(map[string]Article)
+	for _, item := range testItems {
+		if !item.IsDir() {
+			continue
+		}
+
+		f, err := os.Open(fp.Join("test-pages", item.Name(), "source.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parser := NewParser()
+		want[item.Name()], err = parser.Parse(f, fakeHostURL)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inputs := make(chan Input)
+	go func() {
+		defer close(inputs)
+		for name := range want {
+			content, err := os.ReadFile(fp.Join("test-pages", name, "source.html"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			inputs <- Input{ID: name, Reader: bytes.NewReader(content), URL: fakeHostURL}
+		}
+		inputs <- Input{ID: "empty"}
+	}()
+
+	pool := NewPool(NewParser(), 4)
+	nResults := 0
+	for result := range pool.Extract(context.Background(), inputs) {
+		nResults++
+		if result.ID == "empty" {
+			if result.Err == nil {
+				t.Errorf("want error for input without document")
+			}
+			continue
+		}
+
+		if result.Err != nil {
+			t.Errorf("%s: %v", result.ID, result.Err)
+			continue
+		}
+
+		expected := want[result.ID]
+		if result.Article.Title != expected.Title || result.Article.Content != expected.Content {
+			t.Errorf("%s: article is different from sequential extraction", result.ID)
+		}
+	}
+
+	if nResults != len(want)+1 {
+		t.Errorf("want %d results got %d", len(want)+1, nResults)
+	}
+}
+
+func Test_Pool_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The inputs are never closed, so the results are only closed because
+	// the context is canceled.
+	inputs := make(chan Input)
+	for range NewPool(NewParser(), 2).Extract(ctx, inputs) {
+		t.Errorf("want no result from canceled context")
+	}
+}
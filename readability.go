@@ -9,6 +9,7 @@
 package readability
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -38,24 +39,36 @@ type RequestWith func(r *http.Request)
 // FromURL fetch the web page from specified url then parses the response to find
 // the readable content.
 func FromURL(pageURL string, timeout time.Duration, requestModifiers ...RequestWith) (Article, error) {
+	client := &http.Client{Timeout: timeout}
+	return FromURLContext(context.Background(), client, pageURL, requestModifiers...)
+}
+
+// FromURLContext is like FromURL, but fetches the web page using the specified
+// client, which allows using a custom transport, proxy or cookie jar. If client
+// is nil, http.DefaultClient is used. The request is bound to ctx, so canceling
+// ctx aborts both fetching and parsing the page.
+func FromURLContext(ctx context.Context, client *http.Client, pageURL string, requestModifiers ...RequestWith) (Article, error) {
 	// Make sure URL is valid
 	parsedURL, err := nurl.ParseRequestURI(pageURL)
 	if err != nil {
 		return Article{}, fmt.Errorf("failed to parse URL: %v", err)
 	}
 
-	// Fetch page from URL
-	client := &http.Client{Timeout: timeout}
-	req, err := http.NewRequest("GET", pageURL, nil)
-	for _, modifer := range requestModifiers {
-		modifer(req)
+	if client == nil {
+		client = http.DefaultClient
 	}
+
+	// Fetch page from URL
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
 	if err != nil {
 		return Article{}, fmt.Errorf("failed to fetch the page: %v", err)
 	}
+	for _, modifer := range requestModifiers {
+		modifer(req)
+	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return Article{}, fmt.Errorf("failed to fetch the page: %v", err)
+		return Article{}, fmt.Errorf("failed to fetch the page: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -65,9 +78,11 @@ func FromURL(pageURL string, timeout time.Duration, requestModifiers ...RequestW
 		return Article{}, fmt.Errorf("URL is not a HTML document")
 	}
 
-	// Parse content
+	// Transcode content using the charset from Content-Type, if any, then
+	// parse it
 	parser := NewParser()
-	return parser.Parse(resp.Body, parsedURL)
+	parser.ContentLanguage = resp.Header.Get("Content-Language")
+	return parser.parseReader(ctx, resp.Body, cp, parsedURL)
 }
 
 // Check checks whether the input is readable without parsing the whole thing. It's the
@@ -0,0 +1,57 @@
+package readability
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func Test_FromURLContext(t *testing.T) {
+	source, err := os.ReadFile("test-pages/001/source.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Test") != "yes" {
+			http.Error(w, "missing header", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(source)
+	}))
+	defer server.Close()
+
+	withHeader := func(r *http.Request) {
+		r.Header.Set("X-Test", "yes")
+	}
+
+	t.Run("fetch with custom client", func(t *testing.T) {
+		article, err := FromURLContext(context.Background(), server.Client(), server.URL, withHeader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "Get your Frontend JavaScript Code Covered"; article.Title != want {
+			t.Errorf("title, want %q got %q", want, article.Title)
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := FromURLContext(ctx, server.Client(), server.URL, withHeader)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("want context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("invalid URL", func(t *testing.T) {
+		if _, err := FromURLContext(context.Background(), nil, "not a url"); err == nil {
+			t.Error("want error for invalid URL")
+		}
+	})
+}
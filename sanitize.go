@@ -0,0 +1,288 @@
+package readability
+
+import (
+	nurl "net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// SanitizePolicy is an allowlist of the elements, attributes and URLs that may
+// appear in the article content. Set it as Parser.SanitizePolicy to make the
+// content safe to embed into a web page as is.
+type SanitizePolicy struct {
+	// AllowedTags are the names of the elements that are kept. Other
+	// elements are replaced by their children, except for scripts, styles,
+	// embedded objects, SVG, MathML and other elements whose content isn't
+	// readable text, which are removed altogether.
+	AllowedTags []string
+	// AllowedAttributes maps an element name to the names of its allowed
+	// attributes. Attributes listed under "*" are allowed on every element.
+	// Event handler attributes, e.g. onclick, are never allowed. Namespaced
+	// attributes are named with their prefix, e.g. "xlink:href".
+	AllowedAttributes map[string][]string
+	// AllowedURLSchemes are the schemes allowed in attributes that contain
+	// an URL, e.g. href and src. Attributes with any other scheme, like
+	// "javascript:", are removed. Relative URLs are always allowed.
+	AllowedURLSchemes []string
+	// AllowDataImages determines if "data:image/..." URLs are allowed in the
+	// src and srcset attributes of images.
+	AllowDataImages bool
+	// AllowedIframeHosts are the hosts that iframes may be loaded from. A
+	// host also matches all of its subdomains. Iframes from other hosts are
+	// removed, even if "iframe" is one of AllowedTags.
+	AllowedIframeHosts []string
+}
+
+// DefaultSanitizePolicy returns a policy that keeps the common text, list,
+// table and media elements, links to web pages and mail addresses, and
+// iframes from well known video hosts.
+func DefaultSanitizePolicy() *SanitizePolicy {
+	return &SanitizePolicy{
+		AllowedTags: []string{
+			"a", "abbr", "address", "article", "aside", "audio", "b", "bdi", "bdo",
+			"blockquote", "br", "caption", "cite", "code", "col", "colgroup", "data",
+			"dd", "del", "details", "dfn", "div", "dl", "dt", "em", "figcaption",
+			"figure", "footer", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hr",
+			"i", "iframe", "img", "ins", "kbd", "li", "main", "mark", "ol", "p",
+			"picture", "pre", "q", "rp", "rt", "ruby", "s", "samp", "section",
+			"small", "source", "span", "strong", "sub", "summary", "sup", "table",
+			"tbody", "td", "tfoot", "th", "thead", "time", "tr", "track", "u", "ul",
+			"var", "video", "wbr",
+		},
+		AllowedAttributes: map[string][]string{
+			"*":          {"id", "class", "title", "lang", "dir"},
+			"a":          {"href"},
+			"img":        {"src", "srcset", "sizes", "alt", "width", "height"},
+			"source":     {"src", "srcset", "sizes", "type", "media"},
+			"video":      {"src", "poster", "controls", "width", "height"},
+			"audio":      {"src", "controls"},
+			"track":      {"src", "kind", "srclang", "label", "default"},
+			"iframe":     {"src", "width", "height", "allowfullscreen"},
+			"blockquote": {"cite"},
+			"q":          {"cite"},
+			"del":        {"cite", "datetime"},
+			"ins":        {"cite", "datetime"},
+			"td":         {"colspan", "rowspan", "headers"},
+			"th":         {"colspan", "rowspan", "headers", "scope"},
+			"col":        {"span"},
+			"colgroup":   {"span"},
+			"ol":         {"start", "reversed", "type"},
+			"li":         {"value"},
+			"time":       {"datetime"},
+			"data":       {"value"},
+			"details":    {"open"},
+		},
+		AllowedURLSchemes: []string{"http", "https", "mailto"},
+		AllowDataImages:   true,
+		AllowedIframeHosts: []string{
+			"youtube.com", "youtube-nocookie.com", "vimeo.com", "dailymotion.com",
+			"v.qq.com", "bilibili.com", "twitch.tv", "archive.org", "wikimedia.org",
+		},
+	}
+}
+
+// Elements that are removed together with their content when they are not
+// allowed, because their content is not meant to be read as text.
+var sanitizeDropTags = sliceToMap(
+	"script", "style", "template", "noscript", "iframe", "frame", "frameset",
+	"object", "embed", "applet", "svg", "math", "canvas", "head", "title",
+	"meta", "link", "base", "form", "input", "button", "select", "textarea",
+)
+
+// Attributes whose value is an URL.
+var sanitizeURLAttributes = sliceToMap(
+	"href", "src", "cite", "poster", "action", "formaction", "longdesc",
+	"background", "xlink:href", "data",
+)
+
+// sanitizer applies a SanitizePolicy, with its lists converted to sets.
+type sanitizer struct {
+	tags        map[string]struct{}
+	attributes  map[string]map[string]struct{}
+	schemes     map[string]struct{}
+	dataImages  bool
+	iframeHosts []string
+}
+
+// sanitize removes everything from the children and attributes of node that
+// is not allowed by the sanitize policy.
+func (ps *parseState) sanitize(node *html.Node) {
+	policy := ps.SanitizePolicy
+
+	s := sanitizer{
+		tags:        sliceToMap(policy.AllowedTags...),
+		attributes:  make(map[string]map[string]struct{}),
+		schemes:     sliceToMap(policy.AllowedURLSchemes...),
+		dataImages:  policy.AllowDataImages,
+		iframeHosts: policy.AllowedIframeHosts,
+	}
+	for tag, attrs := range policy.AllowedAttributes {
+		s.attributes[tag] = sliceToMap(attrs...)
+	}
+
+	s.sanitizeAttributes(node)
+	s.sanitizeChildren(node)
+}
+
+// sanitizeChildren removes the disallowed descendants of node, and sanitizes
+// the attributes of the remaining ones.
+func (s *sanitizer) sanitizeChildren(node *html.Node) {
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+
+		switch child.Type {
+		case html.TextNode:
+		case html.ElementNode:
+			if s.allowsElement(child) {
+				s.sanitizeAttributes(child)
+				s.sanitizeChildren(child)
+				break
+			}
+
+			if _, drop := sanitizeDropTags[child.Data]; drop || child.Namespace != "" {
+				node.RemoveChild(child)
+				break
+			}
+
+			// Keep the content of the element, e.g. the text of a <font>.
+			s.sanitizeChildren(child)
+			for child.FirstChild != nil {
+				grandChild := child.FirstChild
+				child.RemoveChild(grandChild)
+				node.InsertBefore(grandChild, child)
+			}
+			node.RemoveChild(child)
+		default:
+			// Comments, doctypes and the like
+			node.RemoveChild(child)
+		}
+
+		child = next
+	}
+}
+
+// allowsElement returns true if the element is allowed by the policy.
+func (s *sanitizer) allowsElement(node *html.Node) bool {
+	if _, allowed := s.tags[node.Data]; !allowed {
+		return false
+	}
+
+	if node.Data == "iframe" {
+		for _, attr := range node.Attr {
+			if attr.Namespace == "" && attr.Key == "src" {
+				return s.allowsIframe(attr.Val)
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// allowsIframe returns true if the iframe source is on an allowed host.
+func (s *sanitizer) allowsIframe(src string) bool {
+	u, err := nurl.Parse(strings.TrimSpace(src))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+
+	hostname := strings.ToLower(u.Hostname())
+	for _, host := range s.iframeHosts {
+		host = strings.ToLower(host)
+		if hostname == host || strings.HasSuffix(hostname, "."+host) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeAttributes removes the attributes of node that are not allowed by
+// the policy, or that contain an URL with a disallowed scheme.
+func (s *sanitizer) sanitizeAttributes(node *html.Node) {
+	var attrs []html.Attribute
+	for _, attr := range node.Attr {
+		name := attr.Key
+		if attr.Namespace != "" {
+			name = attr.Namespace + ":" + attr.Key
+		}
+		name = strings.ToLower(name)
+
+		if strings.HasPrefix(name, "on") || !s.allowsAttribute(node.Data, name) {
+			continue
+		}
+
+		isImage := node.Data == "img" || node.Data == "source"
+		switch {
+		case name == "srcset":
+			if !s.allowsSrcset(attr.Val, isImage) {
+				continue
+			}
+		case isURLAttribute(name):
+			if !s.allowsURL(attr.Val, isImage && name == "src") {
+				continue
+			}
+		}
+
+		attrs = append(attrs, attr)
+	}
+	node.Attr = attrs
+}
+
+// allowsAttribute returns true if the attribute is allowed on the element.
+func (s *sanitizer) allowsAttribute(tag, name string) bool {
+	if _, allowed := s.attributes["*"][name]; allowed {
+		return true
+	}
+	_, allowed := s.attributes[tag][name]
+	return allowed
+}
+
+// allowsURL returns true if the URL is relative or has an allowed scheme.
+func (s *sanitizer) allowsURL(rawURL string, isImage bool) bool {
+	// Browsers ignore control characters and whitespace in URLs, so they
+	// can't be used to disguise the scheme, e.g. "java\tscript:".
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, rawURL)
+
+	if cleaned == "" {
+		return true
+	}
+
+	scheme := ""
+	if idx := strings.IndexAny(cleaned, ":/?#"); idx > 0 && cleaned[idx] == ':' {
+		scheme = strings.ToLower(cleaned[:idx])
+	}
+
+	switch {
+	case scheme == "":
+		return true
+	case scheme == "data":
+		return isImage && s.dataImages && rxB64DataURL.MatchString(cleaned) &&
+			strings.HasPrefix(strings.ToLower(cleaned), "data:image/")
+	default:
+		_, allowed := s.schemes[scheme]
+		return allowed
+	}
+}
+
+// allowsSrcset returns true if all of the URLs in the srcset are allowed.
+func (s *sanitizer) allowsSrcset(srcset string, isImage bool) bool {
+	for _, candidate := range strings.Split(srcset, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) > 0 && !s.allowsURL(fields[0], isImage) {
+			return false
+		}
+	}
+	return true
+}
+
+// isURLAttribute returns true if the value of the attribute is an URL.
+func isURLAttribute(name string) bool {
+	_, isURL := sanitizeURLAttributes[name]
+	return isURL
+}
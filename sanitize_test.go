@@ -0,0 +1,71 @@
+package readability
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-shiori/dom"
+)
+
+func Test_sanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "event handlers and data attributes",
+			html: `<p onclick="alert(1)" data-track="x" class="intro" style="color:red">Text</p>`,
+			want: `<p class="intro">Text</p>`,
+		},
+		{
+			name: "javascript urls",
+			html: `<a href="java&#9;script:alert(1)">a</a><a href=" JavaScript:alert(1)">b</a><img src="javascript:alert(1)" alt="c"><a href="https://a.com/">d</a><a href="#top">e</a>`,
+			want: `<a>a</a><a>b</a><img alt="c"/><a href="https://a.com/">d</a><a href="#top">e</a>`,
+		},
+		{
+			name: "data urls",
+			html: `<img src="data:image/png;base64,iVBORw0KGgo="><a href="data:text/html;base64,PHNjcmlwdD4=">x</a>`,
+			want: `<img src="data:image/png;base64,iVBORw0KGgo="/><a>x</a>`,
+		},
+		{
+			name: "disallowed elements",
+			html: `<p>Hello <font color="red">world</font></p><script>alert(1)</script><!-- comment --><form><input name="q"></form>`,
+			want: `<p>Hello world</p>`,
+		},
+		{
+			name: "svg",
+			html: `<p>Icon <svg><a xlink:href="javascript:alert(1)"><text>click</text></a></svg></p>`,
+			want: `<p>Icon </p>`,
+		},
+		{
+			name: "iframes",
+			html: `<iframe src="https://www.youtube.com/embed/x" onload="alert(1)" width="560"></iframe><iframe src="https://evil.com/embed"></iframe><iframe src="https://youtube.com.evil.com/"></iframe>`,
+			want: `<iframe src="https://www.youtube.com/embed/x" width="560"></iframe>`,
+		},
+		{
+			name: "srcset",
+			html: `<img srcset="https://a.com/1.jpg 1x, https://a.com/2.jpg 2x"><img srcset="https://a.com/1.jpg 1x, javascript:alert(1) 2x">`,
+			want: `<img srcset="https://a.com/1.jpg 1x, https://a.com/2.jpg 2x"/><img/>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := dom.Parse(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parser := NewParser()
+			parser.SanitizePolicy = DefaultSanitizePolicy()
+			ps := &parseState{Parser: &parser}
+
+			body := dom.GetElementsByTagName(doc, "body")[0]
+			ps.sanitize(body)
+			if got := dom.InnerHTML(body); got != tt.want {
+				t.Errorf("sanitize()\nwant: %q\ngot : %q", tt.want, got)
+			}
+		})
+	}
+}
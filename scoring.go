@@ -0,0 +1,91 @@
+package readability
+
+import (
+	"strings"
+
+	"github.com/go-shiori/go-readability/internal/re2go"
+)
+
+// Default patterns that are used to score nodes by their class names and IDs.
+// They are matched using fast, compiled-in matchers unless they are replaced
+// by the corresponding regular expression fields of Parser. They are exposed
+// to make it easy to write a replacement that only tweaks a few words.
+const (
+	DefaultUnlikelyCandidatesPattern = `(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote`
+	DefaultMaybeCandidatePattern     = `(?i)and|article|body|column|content|main|mathjax|shadow`
+	DefaultPositiveClassPattern      = `(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`
+	DefaultNegativeClassPattern      = `(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|footer|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|widget`
+	DefaultBylinePattern             = `(?i)byline|author|dateline|writtenby|p-author`
+)
+
+// isUnlikelyCandidate returns true if the class name and ID of a node look
+// like the ones of non-content elements, e.g. comments and sidebars.
+func (ps *Parser) isUnlikelyCandidate(matchString string) bool {
+	if ps.UnlikelyCandidatesRegex != nil {
+		if ps.UnlikelyCandidatesRegex.MatchString(matchString) {
+			return true
+		}
+	} else if re2go.IsUnlikelyCandidates(matchString) {
+		return true
+	}
+	return containsAnyWord(matchString, ps.ExtraUnlikelyCandidates)
+}
+
+// isMaybeCandidate returns true if the class name and ID of a node hint that
+// it might contain content, even though it looks like an unlikely candidate.
+func (ps *Parser) isMaybeCandidate(matchString string) bool {
+	if ps.MaybeCandidateRegex != nil {
+		return ps.MaybeCandidateRegex.MatchString(matchString)
+	}
+	return re2go.MaybeItsACandidate(matchString)
+}
+
+// isPositiveClass returns true if the class name or ID looks like the one of
+// a content element.
+func (ps *Parser) isPositiveClass(str string) bool {
+	if ps.PositiveClassRegex != nil {
+		if ps.PositiveClassRegex.MatchString(str) {
+			return true
+		}
+	} else if re2go.IsPositiveClass(str) {
+		return true
+	}
+	return containsAnyWord(str, ps.ExtraPositiveClasses)
+}
+
+// isNegativeClass returns true if the class name or ID looks like the one of
+// a non-content element.
+func (ps *Parser) isNegativeClass(str string) bool {
+	if ps.NegativeClassRegex != nil {
+		if ps.NegativeClassRegex.MatchString(str) {
+			return true
+		}
+	} else if re2go.IsNegativeClass(str) {
+		return true
+	}
+	return containsAnyWord(str, ps.ExtraNegativeClasses)
+}
+
+// isBylineClass returns true if the class name and ID of a node look like
+// the ones of a byline.
+func (ps *Parser) isBylineClass(matchString string) bool {
+	if ps.BylineRegex != nil {
+		return ps.BylineRegex.MatchString(matchString)
+	}
+	return re2go.IsByline(matchString)
+}
+
+// containsAnyWord reports whether str contains any of words, ignoring case.
+func containsAnyWord(str string, words []string) bool {
+	if len(words) == 0 {
+		return false
+	}
+
+	str = strings.ToLower(str)
+	for _, word := range words {
+		if word != "" && strings.Contains(str, strings.ToLower(word)) {
+			return true
+		}
+	}
+	return false
+}
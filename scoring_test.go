@@ -0,0 +1,153 @@
+package readability
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-shiori/dom"
+)
+
+func Test_defaultScoringPatterns(t *testing.T) {
+	inputs := []string{
+		"article-body", "story-body__inner", "comment-list", "sidebar extra",
+		"hid", "main hid", "hidden", "post-author", "p-author", "mathjax",
+		"ad-break", "-ad-", "com-box", "navigation", "", "Byline",
+	}
+
+	// The default patterns must behave the same as the compiled-in matchers.
+	ps := NewParser()
+	patterns := NewParser()
+	patterns.UnlikelyCandidatesRegex = regexp.MustCompile(DefaultUnlikelyCandidatesPattern)
+	patterns.MaybeCandidateRegex = regexp.MustCompile(DefaultMaybeCandidatePattern)
+	patterns.PositiveClassRegex = regexp.MustCompile(DefaultPositiveClassPattern)
+	patterns.NegativeClassRegex = regexp.MustCompile(DefaultNegativeClassPattern)
+	patterns.BylineRegex = regexp.MustCompile(DefaultBylinePattern)
+
+	for _, input := range inputs {
+		if want, got := ps.isUnlikelyCandidate(input), patterns.isUnlikelyCandidate(input); want != got {
+			t.Errorf("isUnlikelyCandidate(%q): want %v got %v", input, want, got)
+		}
+		if want, got := ps.isMaybeCandidate(input), patterns.isMaybeCandidate(input); want != got {
+			t.Errorf("isMaybeCandidate(%q): want %v got %v", input, want, got)
+		}
+		if want, got := ps.isPositiveClass(input), patterns.isPositiveClass(input); want != got {
+			t.Errorf("isPositiveClass(%q): want %v got %v", input, want, got)
+		}
+		if want, got := ps.isNegativeClass(input), patterns.isNegativeClass(input); want != got {
+			t.Errorf("isNegativeClass(%q): want %v got %v", input, want, got)
+		}
+		if want, got := ps.isBylineClass(input), patterns.isBylineClass(input); want != got {
+			t.Errorf("isBylineClass(%q): want %v got %v", input, want, got)
+		}
+	}
+}
+
+func Test_getClassWeight_customPatterns(t *testing.T) {
+	tests := []struct {
+		name      string
+		html      string
+		configure func(*Parser)
+		want      int
+	}{
+		{
+			name: "default",
+			html: `<div class="cms-inner"></div>`,
+			want: 0,
+		},
+		{
+			name:      "extra positive class",
+			html:      `<div class="CMS-inner"></div>`,
+			configure: func(ps *Parser) { ps.ExtraPositiveClasses = []string{"cms-inner"} },
+			want:      25,
+		},
+		{
+			name:      "extra negative class",
+			html:      `<div id="cms-teaser"></div>`,
+			configure: func(ps *Parser) { ps.ExtraNegativeClasses = []string{"teaser"} },
+			want:      -25,
+		},
+		{
+			name: "replaced negative pattern",
+			html: `<div class="media"></div>`,
+			configure: func(ps *Parser) {
+				pattern := strings.Replace(DefaultNegativeClassPattern, "|media", "", 1)
+				ps.NegativeClassRegex = regexp.MustCompile(pattern)
+			},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := dom.Parse(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parser := NewParser()
+			if tt.configure != nil {
+				tt.configure(&parser)
+			}
+			ps := &parseState{Parser: &parser, flags: flags{useWeightClasses: true}}
+
+			node := dom.QuerySelector(doc, "div")
+			if got := ps.getClassWeight(node); got != tt.want {
+				t.Errorf("getClassWeight() want %d got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_Check_customPatterns(t *testing.T) {
+	text := strings.Repeat("The council met on Tuesday evening to discuss the new budget for the schools. ", 10)
+
+	tests := []struct {
+		name      string
+		html      string
+		configure func(*Parser)
+		want      bool
+	}{
+		{
+			name: "default",
+			html: `<p class="cms-teaser">` + text + `</p>`,
+			want: true,
+		},
+		{
+			name:      "extra unlikely candidate",
+			html:      `<p class="cms-teaser">` + text + `</p>`,
+			configure: func(ps *Parser) { ps.ExtraUnlikelyCandidates = []string{"teaser"} },
+			want:      false,
+		},
+		{
+			name: "replaced unlikely candidates pattern",
+			html: `<p class="sidebar">` + text + `</p>`,
+			configure: func(ps *Parser) {
+				pattern := strings.Replace(DefaultUnlikelyCandidatesPattern, "|sidebar", "", 1)
+				ps.UnlikelyCandidatesRegex = regexp.MustCompile(pattern)
+			},
+			want: true,
+		},
+		{
+			name: "replaced maybe candidate pattern",
+			html: `<p class="sidebar main">` + text + `</p>`,
+			configure: func(ps *Parser) {
+				ps.MaybeCandidateRegex = regexp.MustCompile(`(?i)article`)
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := NewParser()
+			if tt.configure != nil {
+				tt.configure(&ps)
+			}
+
+			if got := ps.Check(strings.NewReader(`<html><body>` + tt.html + `</body></html>`)); got != tt.want {
+				t.Errorf("Check() want %v got %v", tt.want, got)
+			}
+		})
+	}
+}
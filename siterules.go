@@ -0,0 +1,325 @@
+package readability
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	nurl "net/url"
+	"regexp"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+// SiteRule overrides parts of the extraction for the pages of a site that
+// are known to be extracted incorrectly. All selectors are CSS selectors.
+type SiteRule struct {
+	// Hosts are the hostnames that the rule applies to. A hostname also
+	// matches its "www." subdomain, and "*.example.com" matches example.com
+	// and every subdomain of it.
+	Hosts []string `json:"hosts,omitempty"`
+	// URLPattern is a regular expression that the full URL of the page must
+	// match. If Hosts is empty, the rule applies to every page matching it.
+	URLPattern string `json:"urlPattern,omitempty"`
+	// Content selects the elements that contain the article content. When
+	// it matches, the content scoring is skipped and the matched elements
+	// are used as the article content.
+	Content string `json:"content,omitempty"`
+	// Strip selects the elements that are removed before the extraction.
+	Strip []string `json:"strip,omitempty"`
+	// Keep selects the elements that must never be removed while cleaning
+	// up the article content.
+	Keep []string `json:"keep,omitempty"`
+	// Title, Byline and Date select the elements that contain the metadata
+	// of the article. They take precedence over the metadata found in the
+	// <meta> tags and JSON-LD. The date is read from the datetime or content
+	// attribute of the element, or from its text.
+	Title  string `json:"title,omitempty"`
+	Byline string `json:"byline,omitempty"`
+	Date   string `json:"date,omitempty"`
+	// SkipCleanConditionally disables the removal of elements that look
+	// like junk, e.g. forms, link lists and tables with few text.
+	SkipCleanConditionally bool `json:"skipCleanConditionally,omitempty"`
+}
+
+// SiteRules is a registry of site rules. The rule of a page is looked up by
+// its URL, and the first added rule that matches is used.
+type SiteRules struct {
+	rules []*siteRule
+}
+
+// siteRule is a SiteRule whose patterns and selectors have been compiled.
+type siteRule struct {
+	SiteRule
+	urlPattern *regexp.Regexp
+	content    cascadia.Matcher
+	strip      []cascadia.Matcher
+	keep       []cascadia.Matcher
+	title      cascadia.Matcher
+	byline     cascadia.Matcher
+	date       cascadia.Matcher
+}
+
+// NewSiteRules returns a registry that contains the specified rules.
+func NewSiteRules(rules ...SiteRule) (*SiteRules, error) {
+	sr := &SiteRules{}
+	for _, rule := range rules {
+		if err := sr.Add(rule); err != nil {
+			return nil, err
+		}
+	}
+	return sr, nil
+}
+
+// LoadSiteRules reads a JSON array of site rules from r, e.g.:
+//
+//	[{"hosts": ["example.com"], "content": "article .body", "strip": [".ad"]}]
+//
+// The keys of every rule are the JSON names of the SiteRule fields.
+func LoadSiteRules(r io.Reader) (*SiteRules, error) {
+	var rules []SiteRule
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("failed to decode site rules: %v", err)
+	}
+	return NewSiteRules(rules...)
+}
+
+// Add compiles rule and appends it to the registry. It returns an error if
+// the rule doesn't have any host or URL pattern, or if any of its patterns
+// or selectors is invalid.
+func (sr *SiteRules) Add(rule SiteRule) error {
+	if len(rule.Hosts) == 0 && rule.URLPattern == "" {
+		return fmt.Errorf("site rule must have hosts or url pattern")
+	}
+
+	compiled := &siteRule{SiteRule: rule}
+
+	var err error
+	if rule.URLPattern != "" {
+		if compiled.urlPattern, err = regexp.Compile(rule.URLPattern); err != nil {
+			return fmt.Errorf("failed to compile url pattern %q: %v", rule.URLPattern, err)
+		}
+	}
+
+	for _, field := range []struct {
+		selector string
+		sel      *cascadia.Matcher
+	}{
+		{rule.Content, &compiled.content},
+		{rule.Title, &compiled.title},
+		{rule.Byline, &compiled.byline},
+		{rule.Date, &compiled.date},
+	} {
+		if *field.sel, err = compileSelector(field.selector); err != nil {
+			return err
+		}
+	}
+
+	for _, selector := range rule.Strip {
+		sel, err := compileSelector(selector)
+		if err != nil {
+			return err
+		}
+		compiled.strip = append(compiled.strip, sel)
+	}
+
+	for _, selector := range rule.Keep {
+		sel, err := compileSelector(selector)
+		if err != nil {
+			return err
+		}
+		compiled.keep = append(compiled.keep, sel)
+	}
+
+	sr.rules = append(sr.rules, compiled)
+	return nil
+}
+
+// match returns the first rule that applies to pageURL, or nil if there is
+// none.
+func (sr *SiteRules) match(pageURL *nurl.URL) *siteRule {
+	if sr == nil || pageURL == nil {
+		return nil
+	}
+
+	hostname := strings.ToLower(pageURL.Hostname())
+	for _, rule := range sr.rules {
+		if len(rule.Hosts) > 0 && !matchHostname(rule.Hosts, hostname) {
+			continue
+		}
+		if rule.urlPattern != nil && !rule.urlPattern.MatchString(pageURL.String()) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// matchHostname reports whether hostname matches any of hosts.
+func matchHostname(hosts []string, hostname string) bool {
+	for _, host := range hosts {
+		host = strings.ToLower(host)
+		if domain, ok := strings.CutPrefix(host, "*."); ok {
+			if hostname == domain || strings.HasSuffix(hostname, "."+domain) {
+				return true
+			}
+		} else if hostname == host || hostname == "www."+host {
+			return true
+		}
+	}
+	return false
+}
+
+// compileSelector compiles a CSS selector. An empty selector is compiled to
+// nil.
+func compileSelector(selector string) (cascadia.Matcher, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, nil
+	}
+
+	sel, err := cascadia.ParseGroup(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse selector %q: %v", selector, err)
+	}
+	return sel, nil
+}
+
+// metadata returns the metadata of the article found using the title, byline
+// and date selectors of the rule.
+func (rule *siteRule) metadata(doc *html.Node) map[string]string {
+	metadata := make(map[string]string)
+
+	if node := queryFirst(doc, rule.title); node != nil {
+		if title := normalizeWhitespace(dom.TextContent(node)); title != "" {
+			metadata["title"] = title
+		}
+	}
+
+	if node := queryFirst(doc, rule.byline); node != nil {
+		if byline := normalizeWhitespace(dom.TextContent(node)); byline != "" {
+			metadata["byline"] = byline
+		}
+	}
+
+	if node := queryFirst(doc, rule.date); node != nil {
+		date := dom.GetAttribute(node, "datetime")
+		if date == "" {
+			date = dom.GetAttribute(node, "content")
+		}
+		if date == "" {
+			date = dom.TextContent(node)
+		}
+		if date = strings.TrimSpace(date); date != "" {
+			metadata["publishedTime"] = date
+		}
+	}
+
+	return metadata
+}
+
+// prepare removes the elements matching the strip selectors from doc, and
+// marks the elements matching the keep selectors so they survive the clean
+// up of the article content.
+func (rule *siteRule) prepare(doc *html.Node) {
+	for _, sel := range rule.strip {
+		for _, node := range cascadia.QueryAll(doc, sel) {
+			if node.Parent != nil {
+				node.Parent.RemoveChild(node)
+			}
+		}
+	}
+
+	for _, sel := range rule.keep {
+		for _, node := range cascadia.QueryAll(doc, sel) {
+			dom.SetAttribute(node, "data-readability-keep", "true")
+		}
+	}
+}
+
+// grabSiteContent returns the article content selected by the content
+// selector of the site rule, wrapped up in a div the same way as grabArticle.
+// It returns nil if there is no such selector, or if it doesn't match.
+func (ps *parseState) grabSiteContent() *html.Node {
+	if ps.siteRule == nil || ps.siteRule.content == nil {
+		return nil
+	}
+
+	nodes := cascadia.QueryAll(ps.doc, ps.siteRule.content)
+	if len(nodes) == 0 {
+		ps.logf("site content selector %q doesn't match\n", ps.siteRule.Content)
+		return nil
+	}
+
+	ps.articleLang = dom.GetAttribute(dom.DocumentElement(ps.doc), "lang")
+	ps.startAttemptReport()
+
+	page := dom.CreateElement("div")
+	dom.SetAttribute(page, "id", "readability-page-1")
+	dom.SetAttribute(page, "class", "page")
+
+nodeLoop:
+	for _, node := range nodes {
+		// Nested matches are already included in their ancestor.
+		for ancestor := node.Parent; ancestor != nil; ancestor = ancestor.Parent {
+			if dom.IncludeNode(nodes, ancestor) {
+				continue nodeLoop
+			}
+		}
+		dom.AppendChild(page, dom.Clone(node, true))
+	}
+
+	articleContent := dom.CreateElement("div")
+	dom.AppendChild(articleContent, page)
+
+	ps.phase = "prepArticle"
+	ps.prepArticle(articleContent)
+	ps.phase = "grabArticle"
+
+	if report := ps.currentAttemptReport(); report != nil {
+		report.TextLength, _ = countCharsAndCommas(articleContent)
+		ps.diagnostics.SelectedAttempt = 0
+	}
+
+	return articleContent
+}
+
+// collectKeptNodes adds the elements of root that the site rule requires to
+// be kept, and their ancestors, to the kept nodes, so isKept doesn't have to
+// search the subtree of every node. It's called again when the content is
+// assembled, since it's wrapped in new elements.
+func (ps *parseState) collectKeptNodes(root *html.Node) {
+	if ps.siteRule == nil || len(ps.siteRule.keep) == 0 {
+		return
+	}
+	if ps.keptNodes == nil {
+		ps.keptNodes = make(map[*html.Node]struct{})
+	}
+
+	for _, node := range dom.QuerySelectorAll(root, "[data-readability-keep]") {
+		for ; node != nil && node.Type == html.ElementNode; node = node.Parent {
+			ps.keptNodes[node] = struct{}{}
+		}
+	}
+}
+
+// isKept reports whether node is, or contains, an element that the site rule
+// requires to be kept.
+func (ps *parseState) isKept(node *html.Node) bool {
+	if len(ps.keptNodes) == 0 {
+		return false
+	}
+	_, kept := ps.keptNodes[node]
+	return kept
+}
+
+// queryFirst returns the first element in doc matched by sel.
+func queryFirst(doc *html.Node, sel cascadia.Matcher) *html.Node {
+	if sel == nil {
+		return nil
+	}
+	return cascadia.Query(doc, sel)
+}
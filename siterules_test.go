@@ -0,0 +1,160 @@
+package readability
+
+import (
+	nurl "net/url"
+	"strings"
+	"testing"
+)
+
+const siteRulesTestPage = `<html><head><title>Generic site title</title></head><body>
+<div class="header"><h1 class="headline">The real headline</h1>
+<span class="author">Jane Doe</span>
+<time datetime="2023-04-05T06:07:08Z">April 5</time></div>
+<div class="promo"><p>Subscribe to our newsletter to read more stories like this one, it's free and you can cancel at any time you want.</p></div>
+<div class="story">
+<p>First paragraph of the story, which is long enough to be considered content by the parser when scoring nodes.</p>
+<div class="related"><a href="/a">Related one</a> <a href="/b">Related two</a> <a href="/c">Related three</a></div>
+<p>Second paragraph of the story, which is also long enough to be considered content by the parser when scoring.</p>
+</div>
+</body></html>`
+
+func Test_SiteRules(t *testing.T) {
+	rules, err := LoadSiteRules(strings.NewReader(`[
+		{"hosts": ["other.com"], "content": "body"},
+		{
+			"hosts": ["*.example.com"],
+			"content": ".story",
+			"strip": [".promo"],
+			"keep": [".related"],
+			"title": ".headline",
+			"byline": ".author",
+			"date": "time"
+		}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser := NewParser()
+	parser.SiteRules = rules
+
+	pageURL, _ := nurl.Parse("https://news.example.com/story")
+	article, err := parser.Parse(strings.NewReader(siteRulesTestPage), pageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if article.Title != "The real headline" {
+		t.Errorf("title: want %q got %q", "The real headline", article.Title)
+	}
+	if article.Byline != "Jane Doe" {
+		t.Errorf("byline: want %q got %q", "Jane Doe", article.Byline)
+	}
+	if article.PublishedTime == nil || article.PublishedTime.Year() != 2023 {
+		t.Errorf("published time: want 2023-04-05 got %v", article.PublishedTime)
+	}
+	if strings.Contains(article.Content, "newsletter") {
+		t.Errorf("content contains stripped element: %s", article.Content)
+	}
+	if !strings.Contains(article.Content, "Related three") {
+		t.Errorf("content doesn't contain kept element: %s", article.Content)
+	}
+	if strings.Contains(article.Content, "data-readability-keep") {
+		t.Errorf("content contains readability attribute: %s", article.Content)
+	}
+
+	// Pages of other sites are parsed as usual.
+	pageURL, _ = nurl.Parse("https://example.org/story")
+	article, err = parser.Parse(strings.NewReader(siteRulesTestPage), pageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.Title != "Generic site title" {
+		t.Errorf("title: want %q got %q", "Generic site title", article.Title)
+	}
+}
+
+func Test_SiteRules_match(t *testing.T) {
+	rules, err := NewSiteRules(
+		SiteRule{Hosts: []string{"example.com"}, Title: "h1"},
+		SiteRule{Hosts: []string{"*.example.org"}, Title: "h2"},
+		SiteRule{URLPattern: `^https://blog\.test/\d+/`, Title: "h3"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.com/a", "h1"},
+		{"https://WWW.example.com:8080/a", "h1"},
+		{"https://sub.example.com/a", ""},
+		{"https://example.org/a", "h2"},
+		{"https://a.b.example.org/a", "h2"},
+		{"https://notexample.org/a", ""},
+		{"https://blog.test/2023/post", "h3"},
+		{"https://blog.test/about", ""},
+	}
+
+	for _, tt := range tests {
+		pageURL, _ := nurl.Parse(tt.url)
+		got := ""
+		if rule := rules.match(pageURL); rule != nil {
+			got = rule.Title
+		}
+		if got != tt.want {
+			t.Errorf("match(%q): want %q got %q", tt.url, tt.want, got)
+		}
+	}
+}
+
+func Test_SiteRules_invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{"no host", `[{"content": "article"}]`},
+		{"bad selector", `[{"hosts": ["a.com"], "content": "div["}]`},
+		{"bad pattern", `[{"urlPattern": "("}]`},
+		{"unknown key", `[{"hosts": ["a.com"], "contnet": "article"}]`},
+	}
+
+	for _, tt := range tests {
+		if _, err := LoadSiteRules(strings.NewReader(tt.rules)); err == nil {
+			t.Errorf("%s: want error got nil", tt.name)
+		}
+	}
+}
+
+func Test_SiteRules_keep(t *testing.T) {
+	paragraphs := strings.Repeat("<p>A paragraph of the story, which is long enough to be considered content by the parser, with commas, when scoring nodes.</p>\n", 6)
+	page := `<html><body><div class="story">` + paragraphs +
+		`<div class="comment-note"><p>Editor's note: this story was corrected.</p></div>` + paragraphs + `</div></body></html>`
+	pageURL, _ := nurl.Parse("https://example.org/story")
+
+	parser := NewParser()
+	article, err := parser.Parse(strings.NewReader(page), pageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(article.Content, "this story was corrected") {
+		t.Fatalf("content contains unlikely candidate without site rule: %s", article.Content)
+	}
+
+	// Kept elements also survive the scoring of the content, when the
+	// content isn't selected by the rule
+	parser.SiteRules, err = LoadSiteRules(strings.NewReader(`[{"urlPattern": "^https://example\\.org/", "keep": [".comment-note"]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	article, err = parser.Parse(strings.NewReader(page), pageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(article.Content, "this story was corrected") {
+		t.Errorf("content doesn't contain kept element: %s", article.Content)
+	}
+}
@@ -0,0 +1,105 @@
+package readability
+
+import "golang.org/x/net/html"
+
+// docSnapshot records the state of a document, so the document can be put
+// back the way it was after being mutated. Unlike a deep clone, it doesn't
+// create new nodes: only the elements are recorded, with their tag name,
+// attributes and children. The other nodes, e.g. text nodes, are never
+// modified, so they only have to be put back in their place.
+type docSnapshot struct {
+	elements []savedElement
+	children []*html.Node
+	attrs    []html.Attribute
+}
+
+// savedElement is the state of an element, or of the document node. Its
+// attributes and children are the next nAttrs and nChildren items of the
+// attrs and children of the snapshot.
+type savedElement struct {
+	node      *html.Node
+	data      string
+	nAttrs    int
+	nChildren int
+}
+
+// newDocSnapshot records the current state of root and its descendants.
+func newDocSnapshot(root *html.Node) *docSnapshot {
+	nElements, nChildren, nAttrs := 1, 0, len(root.Attr)
+	for node := range root.Descendants() {
+		nChildren++
+		if canHaveChildren(node) {
+			nElements++
+			nAttrs += len(node.Attr)
+		}
+	}
+
+	s := &docSnapshot{
+		elements: make([]savedElement, 0, nElements),
+		children: make([]*html.Node, 0, nChildren),
+		attrs:    make([]html.Attribute, 0, nAttrs),
+	}
+
+	s.add(root)
+	for node := range root.Descendants() {
+		if canHaveChildren(node) {
+			s.add(node)
+		}
+	}
+
+	return s
+}
+
+// restore puts every recorded node back in the state it had when the
+// snapshot was taken. Nodes that were created since then are left out of
+// the document. The snapshot can be restored several times.
+func (s *docSnapshot) restore() {
+	// The attributes are modified in place by dom.SetAttribute and
+	// dom.RemoveAttribute, so the nodes get a copy of them to keep the
+	// snapshot intact.
+	attrs := append([]html.Attribute(nil), s.attrs...)
+	children := s.children
+	for _, saved := range s.elements {
+		node := saved.node
+		node.Data = saved.data
+		node.Attr, attrs = attrs[:saved.nAttrs:saved.nAttrs], attrs[saved.nAttrs:]
+
+		node.FirstChild, node.LastChild = nil, nil
+		var prev *html.Node
+		for _, child := range children[:saved.nChildren] {
+			child.Parent, child.PrevSibling = node, prev
+			if prev == nil {
+				node.FirstChild = child
+			} else {
+				prev.NextSibling = child
+			}
+			prev = child
+		}
+		if prev != nil {
+			prev.NextSibling = nil
+			node.LastChild = prev
+		}
+		children = children[saved.nChildren:]
+	}
+}
+
+// add records the current state of node.
+func (s *docSnapshot) add(node *html.Node) {
+	saved := savedElement{
+		node:   node,
+		data:   node.Data,
+		nAttrs: len(node.Attr),
+	}
+	s.attrs = append(s.attrs, node.Attr...)
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		s.children = append(s.children, child)
+		saved.nChildren++
+	}
+	s.elements = append(s.elements, saved)
+}
+
+// canHaveChildren returns true if node can have children, i.e. if it's an
+// element or a document.
+func canHaveChildren(node *html.Node) bool {
+	return node.Type == html.ElementNode || node.Type == html.DocumentNode
+}
@@ -0,0 +1,46 @@
+package readability
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-shiori/dom"
+)
+
+func Test_docSnapshot(t *testing.T) {
+	page := `<html lang="en"><head><title>Title</title></head><body>` +
+		`<div id="main" class="content"><p>First <b>paragraph</b></p><p class="note">Second</p></div>` +
+		`<aside>Sidebar</aside></body></html>`
+
+	doc, err := dom.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := dom.OuterHTML(doc)
+	snapshot := newDocSnapshot(doc)
+
+	for i := 0; i < 2; i++ {
+		main := dom.GetElementByID(doc, "main")
+		dom.SetAttribute(main, "class", "changed")
+		dom.SetAttribute(main, "data-readability-score", "10")
+		dom.RemoveAttribute(dom.QuerySelector(doc, ".note"), "class")
+		dom.QuerySelector(doc, "p").Data = "div"
+		dom.QuerySelector(doc, "aside").Parent.RemoveChild(dom.QuerySelector(doc, "aside"))
+
+		wrapper := dom.CreateElement("section")
+		for main.FirstChild != nil {
+			dom.AppendChild(wrapper, main.FirstChild)
+		}
+		dom.AppendChild(dom.QuerySelector(doc, "head"), wrapper)
+
+		if got := dom.OuterHTML(doc); got == want {
+			t.Fatalf("document wasn't mutated")
+		}
+
+		snapshot.restore()
+		if got := dom.OuterHTML(doc); got != want {
+			t.Errorf("restore #%d\nwant: %q\ngot : %q", i+1, want, got)
+		}
+	}
+}
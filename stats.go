@@ -0,0 +1,258 @@
+package readability
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+// defaultWordsPerMinute is the reading speed of the languages that don't
+// have one in readingSpeeds.
+const defaultWordsPerMinute = 200
+
+// readingSpeeds are the average silent reading speeds measured by
+// Trauzettel-Klosinski and Dietz in "Standardized Assessment of Reading
+// Performance: The New International Reading Speed Texts IReST", by
+// language. The speeds of Chinese and Japanese are in characters per minute,
+// as each of their characters is counted as a word.
+var readingSpeeds = map[string]int{
+	"ar": 138,
+	"de": 179,
+	"en": 228,
+	"es": 218,
+	"fi": 161,
+	"fr": 195,
+	"he": 187,
+	"it": 188,
+	"ja": 357,
+	"nl": 202,
+	"pl": 166,
+	"pt": 181,
+	"ru": 184,
+	"sv": 199,
+	"tr": 166,
+	"zh": 255,
+}
+
+// textStats are the statistics of the text of an article.
+type textStats struct {
+	words      int
+	sentences  int
+	paragraphs int
+	// latinWords and syllables are the number of words written in the
+	// Latin script, and their number of syllables.
+	latinWords int
+	syllables  int
+	// letters and latinLetters are the number of letters, and how many of
+	// them are in the Latin script.
+	letters      int
+	latinLetters int
+}
+
+// getTextStats counts the words, sentences and paragraphs of the text of
+// node. Headings are counted as sentences, but not as paragraphs.
+func getTextStats(node *html.Node) textStats {
+	var stats textStats
+	for _, block := range textBlocks(node) {
+		stats.add(block.text)
+		switch block.tag {
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+		default:
+			stats.paragraphs++
+		}
+	}
+	return stats
+}
+
+// add counts the words and sentences of a block of text. Every Chinese or
+// Japanese character counts as a word, since these languages aren't written
+// with spaces between words. The text after the last sentence terminator
+// counts as a sentence, e.g. a heading or a list item.
+func (stats *textStats) add(text string) {
+	var word []rune
+	endWord := func() {
+		if len(word) == 0 {
+			return
+		}
+
+		stats.words++
+		for _, r := range word {
+			if unicode.Is(unicode.Latin, r) {
+				stats.latinWords++
+				stats.syllables += syllableCount(string(word))
+				break
+			}
+		}
+		word = word[:0]
+	}
+
+	runes := []rune(text)
+	openSentence := false
+	for i, r := range runes {
+		switch {
+		case isCJK(r):
+			endWord()
+			stats.words++
+			stats.letters++
+			openSentence = true
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+			if unicode.IsLetter(r) {
+				stats.letters++
+				if unicode.Is(unicode.Latin, r) {
+					stats.latinLetters++
+				}
+			}
+			openSentence = true
+		case len(word) > 0 && strings.ContainsRune("'’-", r):
+			// Apostrophes and hyphens inside a word, e.g. "don't"
+			word = append(word, r)
+		case len(word) > 0 && strings.ContainsRune(".,", r) && unicode.IsDigit(word[len(word)-1]) &&
+			i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			// Separators inside a number, e.g. "2.5" or "1,000"
+			word = append(word, r)
+		default:
+			endWord()
+			if strings.ContainsRune("。！？", r) ||
+				strings.ContainsRune(".!?…", r) && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])) {
+				if openSentence {
+					stats.sentences++
+				}
+				openSentence = false
+			}
+		}
+	}
+	endWord()
+
+	if openSentence {
+		stats.sentences++
+	}
+}
+
+// readingTime returns the time needed to read words at the reading speed of
+// lang, rounded to the second.
+func (ps *Parser) readingTime(words int, lang string) time.Duration {
+	if words == 0 {
+		return 0
+	}
+
+	primary, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	wpm := ps.WordsPerMinute[primary]
+	if wpm <= 0 {
+		wpm = readingSpeeds[primary]
+	}
+	if wpm <= 0 {
+		wpm = ps.WordsPerMinute[""]
+	}
+	if wpm <= 0 {
+		wpm = defaultWordsPerMinute
+	}
+
+	duration := time.Duration(float64(words) / float64(wpm) * float64(time.Minute))
+	return duration.Round(time.Second)
+}
+
+// readabilityGrade returns the Flesch-Kincaid grade level of the text, i.e.
+// the number of years of education needed to understand it. It's only
+// meaningful for the languages written in the Latin script, so it's 0 for
+// the other ones.
+func (stats textStats) readabilityGrade() float64 {
+	if stats.sentences == 0 || stats.latinWords == 0 || stats.latinLetters*2 < stats.letters {
+		return 0
+	}
+
+	wordsPerSentence := float64(stats.latinWords) / float64(stats.sentences)
+	syllablesPerWord := float64(stats.syllables) / float64(stats.latinWords)
+	grade := 0.39*wordsPerSentence + 11.8*syllablesPerWord - 15.59
+	return math.Round(math.Max(grade, 0)*10) / 10
+}
+
+// textBlock is the text of a block element, with the name of its tag.
+type textBlock struct {
+	tag  string
+	text string
+}
+
+// textBlocks splits the text of node into the text of its block elements,
+// e.g. paragraphs, headings, list items or table cells.
+func textBlocks(node *html.Node) []textBlock {
+	var blocks []textBlock
+	var sb strings.Builder
+
+	flush := func(tag string) {
+		if text := strings.TrimSpace(sb.String()); text != "" {
+			blocks = append(blocks, textBlock{tag: tag, text: text})
+		}
+		sb.Reset()
+	}
+
+	var walk func(*html.Node, string)
+	walk = func(parent *html.Node, tag string) {
+		for child := parent.FirstChild; child != nil; child = child.NextSibling {
+			switch child.Type {
+			case html.TextNode:
+				sb.WriteString(child.Data)
+				continue
+			case html.ElementNode:
+			default:
+				continue
+			}
+
+			switch childTag := dom.TagName(child); childTag {
+			case "script", "style", "template", "noscript":
+			case "br":
+				sb.WriteByte('\n')
+			case "tr", "td", "th", "caption":
+				flush(tag)
+				walk(child, childTag)
+				flush(childTag)
+			default:
+				if !isBlockElement(child) {
+					walk(child, tag)
+					continue
+				}
+				flush(tag)
+				walk(child, childTag)
+				flush(childTag)
+			}
+		}
+	}
+	walk(node, dom.TagName(node))
+	flush(dom.TagName(node))
+
+	return blocks
+}
+
+// syllableCount estimates the number of syllables of a word by counting its
+// groups of vowels. A final silent "e", like in "make", isn't counted.
+func syllableCount(word string) int {
+	word = strings.ToLower(word)
+
+	count := 0
+	inVowels := false
+	for _, r := range word {
+		isVowel := strings.ContainsRune("aeiouyàáâãäåæèéêëìíîïòóôõöøœùúûüýÿ", r)
+		if isVowel && !inVowels {
+			count++
+		}
+		inVowels = isVowel
+	}
+
+	if count > 1 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") &&
+		!strings.HasSuffix(word, "ee") {
+		count--
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
+
+// isCJK reports whether r is a Chinese character or a Japanese kana.
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
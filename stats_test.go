@@ -0,0 +1,175 @@
+package readability
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-shiori/dom"
+)
+
+func Test_getTextStats(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		html           string
+		wantWords      int
+		wantSentences  int
+		wantParagraphs int
+	}{
+		{
+			name:           "paragraphs and heading",
+			html:           `<h2>A title</h2><p>The cat sat. It didn't move!</p><p>Version 2.5 is out…</p>`,
+			wantWords:      12,
+			wantSentences:  4,
+			wantParagraphs: 2,
+		},
+		{
+			name:           "inline elements don't split words",
+			html:           `<p>Read<b>ability</b> is <a href="#">fun</a></p>`,
+			wantWords:      3,
+			wantSentences:  1,
+			wantParagraphs: 1,
+		},
+		{
+			name:           "list items and table cells",
+			html:           `<ul><li>one</li><li>two</li></ul><table><tr><td>three</td><td>four</td></tr></table>`,
+			wantWords:      4,
+			wantSentences:  4,
+			wantParagraphs: 4,
+		},
+		{
+			name:           "chinese characters are words",
+			html:           `<p>今天天气很好。我们去公园吧！</p>`,
+			wantWords:      12,
+			wantSentences:  2,
+			wantParagraphs: 1,
+		},
+		{
+			name:           "japanese mixed with latin",
+			html:           `<p>これはGoのテストです。</p>`,
+			wantWords:      10,
+			wantSentences:  1,
+			wantParagraphs: 1,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			doc, err := dom.Parse(strings.NewReader("<div>" + scenario.html + "</div>"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stats := getTextStats(dom.QuerySelector(doc, "div"))
+			if stats.words != scenario.wantWords {
+				t.Errorf("words: want %d got %d", scenario.wantWords, stats.words)
+			}
+			if stats.sentences != scenario.wantSentences {
+				t.Errorf("sentences: want %d got %d", scenario.wantSentences, stats.sentences)
+			}
+			if stats.paragraphs != scenario.wantParagraphs {
+				t.Errorf("paragraphs: want %d got %d", scenario.wantParagraphs, stats.paragraphs)
+			}
+		})
+	}
+}
+
+func Test_syllableCount(t *testing.T) {
+	scenarios := map[string]int{
+		"cat":         1,
+		"make":        1,
+		"table":       2,
+		"readability": 5,
+		"rhythm":      1,
+		"café":        2,
+	}
+
+	for word, want := range scenarios {
+		if got := syllableCount(word); got != want {
+			t.Errorf("%s: want %d got %d", word, want, got)
+		}
+	}
+}
+
+func Test_readabilityGrade(t *testing.T) {
+	var simple textStats
+	simple.add("The cat sat on the mat. The dog ran to the cat.")
+	if grade := simple.readabilityGrade(); grade != 0 {
+		t.Errorf("simple text: want grade 0 got %v", grade)
+	}
+
+	var complex textStats
+	complex.add("Comprehensive international collaboration necessitates considerable organizational flexibility, institutional accountability and unprecedented interdisciplinary communication.")
+	if grade := complex.readabilityGrade(); grade < 16 {
+		t.Errorf("complex text: want grade above 16 got %v", grade)
+	}
+
+	var chinese textStats
+	chinese.add("今天天气很好。我们去公园吧！")
+	if grade := chinese.readabilityGrade(); grade != 0 {
+		t.Errorf("chinese text: want grade 0 got %v", grade)
+	}
+}
+
+func Test_readingTime(t *testing.T) {
+	parser := NewParser()
+	scenarios := []struct {
+		words int
+		lang  string
+		want  time.Duration
+	}{
+		{0, "en", 0},
+		{228, "en-US", time.Minute},
+		{357, "ja", time.Minute},
+		{100, "xx", 30 * time.Second},
+		{100, "", 30 * time.Second},
+	}
+	for _, scenario := range scenarios {
+		if got := parser.readingTime(scenario.words, scenario.lang); got != scenario.want {
+			t.Errorf("%d words in %q: want %v got %v", scenario.words, scenario.lang, scenario.want, got)
+		}
+	}
+
+	parser.WordsPerMinute = map[string]int{"en": 300, "": 100}
+	if got := parser.readingTime(300, "en-GB"); got != time.Minute {
+		t.Errorf("custom english speed: want %v got %v", time.Minute, got)
+	}
+	if got := parser.readingTime(100, "xx"); got != time.Minute {
+		t.Errorf("custom default speed: want %v got %v", time.Minute, got)
+	}
+	if got := parser.readingTime(255, "zh"); got != time.Minute {
+		t.Errorf("known speed with custom default: want %v got %v", time.Minute, got)
+	}
+}
+
+func Test_Article_ReadingTime_JSON(t *testing.T) {
+	html := `<html lang="en"><body><article><p>` +
+		strings.Repeat("The council met on Tuesday evening to discuss the new school budget. ", 19) +
+		`</p></article></body></html>`
+
+	parser := NewParser()
+	article, err := parser.Parse(strings.NewReader(html), fakeHostURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.ReadingTime != time.Minute {
+		t.Fatalf("reading time, want %v got %v", time.Minute, article.ReadingTime)
+	}
+
+	data, err := json.Marshal(article)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if got := fields["readingTimeSeconds"]; got != float64(60) {
+		t.Errorf("readingTimeSeconds, want 60 got %v", got)
+	}
+	if got, exists := fields["readingTime"]; exists {
+		t.Errorf("readingTime should not be encoded, got %v", got)
+	}
+}
@@ -0,0 +1,249 @@
+package readability
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-shiori/dom"
+	"golang.org/x/net/html"
+)
+
+// TextOptions configures how RenderText formats the plain text.
+type TextOptions struct {
+	// LineWidth is the maximum number of characters in a line. Longer lines
+	// are wrapped at word boundaries. Default: 0 (no wrapping)
+	LineWidth int
+	// ListMarker is the marker written before items of unordered lists.
+	// Items of ordered lists are always prefixed with their number.
+	// Default: "-"
+	ListMarker string
+}
+
+// Text renders the readable content of the article as plain text. Unlike
+// TextContent, paragraphs, headings and list items are separated from each
+// other. See RenderText for details.
+func (article Article) Text(opts TextOptions) string {
+	if article.Node == nil {
+		return ""
+	}
+	return RenderText(article.Node, opts)
+}
+
+// RenderText converts node and its descendants into plain text. Block level
+// elements are separated by blank lines, list items are prefixed with a list
+// marker, table cells are separated by tabs and whitespace inside <pre> is
+// kept as is.
+func RenderText(node *html.Node, opts TextOptions) string {
+	if opts.ListMarker == "" {
+		opts.ListMarker = "-"
+	}
+
+	r := &textRenderer{opts: opts}
+	out := r.blocks(node, 0)
+	if out == "" {
+		return ""
+	}
+	return out + "\n"
+}
+
+// textRenderer converts HTML nodes into plain text blocks.
+type textRenderer struct {
+	opts TextOptions
+}
+
+// blocks renders all children of node as a sequence of blocks separated by
+// blank lines. The indent is the number of columns that the caller will use
+// to indent the result, so lines can be wrapped accordingly.
+func (r *textRenderer) blocks(node *html.Node, indent int) string {
+	return strings.Join(r.blockParts(node, indent), "\n\n")
+}
+
+// blockParts renders all children of node as a list of text blocks.
+func (r *textRenderer) blockParts(node *html.Node, indent int) []string {
+	var parts []string
+	var inline strings.Builder
+
+	flushInline := func() {
+		if text := r.wrap(inline.String(), indent); text != "" {
+			parts = append(parts, text)
+		}
+		inline.Reset()
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && isBlockElement(child) {
+			flushInline()
+			if text := r.block(child, indent); text != "" {
+				parts = append(parts, text)
+			}
+			continue
+		}
+		inline.WriteString(r.inline(child))
+	}
+	flushInline()
+
+	return parts
+}
+
+// block renders a single block level element.
+func (r *textRenderer) block(node *html.Node, indent int) string {
+	switch tag := dom.TagName(node); tag {
+	case "p", "h1", "h2", "h3", "h4", "h5", "h6", "dt", "figcaption", "caption":
+		return r.wrap(r.inlineChildren(node), indent)
+	case "hr":
+		return ""
+	case "pre":
+		return strings.Trim(preText(node), "\n")
+	case "blockquote", "dd":
+		return prefixLines(r.blocks(node, indent+2), "  ", "")
+	case "ul", "ol":
+		return r.list(node, indent, tag == "ol")
+	case "table":
+		return r.table(node, indent)
+	case "script", "style", "template", "noscript", "head":
+		return ""
+	default:
+		return r.blocks(node, indent)
+	}
+}
+
+// list renders each list item on its own line, prefixed with list marker.
+func (r *textRenderer) list(node *html.Node, indent int, ordered bool) string {
+	start := 1
+	if ordered {
+		if n, err := strconv.Atoi(dom.GetAttribute(node, "start")); err == nil {
+			start = n
+		}
+	}
+
+	var items []string
+	for _, li := range dom.Children(node) {
+		if dom.TagName(li) != "li" {
+			continue
+		}
+
+		marker := r.opts.ListMarker + " "
+		if ordered {
+			marker = strconv.Itoa(start+len(items)) + ". "
+		}
+
+		separator := "\n\n"
+		if isTightListItem(li) {
+			separator = "\n"
+		}
+
+		markerWidth := utf8.RuneCountInString(marker)
+		content := strings.Join(r.blockParts(li, indent+markerWidth), separator)
+		if content == "" {
+			continue
+		}
+
+		padding := strings.Repeat(" ", markerWidth)
+		items = append(items, marker+prefixLines(content, padding, "")[len(padding):])
+	}
+
+	return strings.Join(items, "\n")
+}
+
+// table renders every table row on its own line, with cells separated by tab.
+func (r *textRenderer) table(node *html.Node, indent int) string {
+	var rows []string
+	for _, child := range dom.QuerySelectorAll(node, "caption, tr") {
+		// Rows of nested tables are rendered as part of their parent cell.
+		if closestTable(child) != node {
+			continue
+		}
+
+		if dom.TagName(child) == "caption" {
+			if text := r.wrap(r.inlineChildren(child), indent); text != "" {
+				rows = append(rows, text)
+			}
+			continue
+		}
+
+		var cells []string
+		for _, cell := range dom.Children(child) {
+			if tag := dom.TagName(cell); tag == "td" || tag == "th" {
+				cells = append(cells, normalizeWhitespace(r.inlineChildren(cell)))
+			}
+		}
+
+		if row := strings.Join(cells, "\t"); strings.TrimSpace(row) != "" {
+			rows = append(rows, row)
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+// inlineChildren renders all children of node as phrasing content.
+func (r *textRenderer) inlineChildren(node *html.Node) string {
+	var sb strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(r.inline(child))
+	}
+	return sb.String()
+}
+
+// inline renders node as phrasing content. Line breaks are kept as new line
+// characters, every other whitespace is collapsed later on.
+func (r *textRenderer) inline(node *html.Node) string {
+	switch node.Type {
+	case html.TextNode:
+		// New lines in the source are just whitespace, only <br> starts
+		// a new line.
+		return strings.NewReplacer("\r", " ", "\n", " ").Replace(node.Data)
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch dom.TagName(node) {
+	case "br":
+		return "\n"
+	case "script", "style", "template", "noscript":
+		return ""
+	default:
+		if isBlockElement(node) {
+			return " " + r.inlineChildren(node) + " "
+		}
+		return r.inlineChildren(node)
+	}
+}
+
+// wrap collapses the whitespace of text, then breaks it into lines that fit
+// within the configured line width. Explicit line breaks are preserved.
+func (r *textRenderer) wrap(text string, indent int) string {
+	width := r.opts.LineWidth - indent
+	if r.opts.LineWidth > 0 && width < 1 {
+		width = 1
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			continue
+		}
+
+		if r.opts.LineWidth <= 0 {
+			lines = append(lines, strings.Join(words, " "))
+			continue
+		}
+
+		current, currentWidth := words[0], utf8.RuneCountInString(words[0])
+		for _, word := range words[1:] {
+			wordWidth := utf8.RuneCountInString(word)
+			if currentWidth+1+wordWidth > width {
+				lines = append(lines, current)
+				current, currentWidth = word, wordWidth
+				continue
+			}
+			current += " " + word
+			currentWidth += 1 + wordWidth
+		}
+		lines = append(lines, current)
+	}
+
+	return strings.Join(lines, "\n")
+}
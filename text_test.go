@@ -0,0 +1,54 @@
+package readability
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-shiori/dom"
+)
+
+func Test_RenderText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		opts TextOptions
+		want string
+	}{
+		{
+			name: "blocks are separated",
+			html: "<h2>Heading</h2><p>First\n  paragraph.</p><div>Second<br>line</div>",
+			want: "Heading\n\nFirst paragraph.\n\nSecond\nline\n",
+		},
+		{
+			name: "list markers",
+			html: `<ul><li>One<ul><li>Nested</li></ul></li><li>Two</li></ul><ol start="9"><li>Nine</li><li>Ten</li></ol>`,
+			opts: TextOptions{ListMarker: "•"},
+			want: "• One\n  • Nested\n• Two\n\n9. Nine\n10. Ten\n",
+		},
+		{
+			name: "line width",
+			html: `<p>The quick brown fox jumps over the lazy dog.</p><ul><li>The quick brown fox jumps</li></ul>`,
+			opts: TextOptions{LineWidth: 16},
+			want: "The quick brown\nfox jumps over\nthe lazy dog.\n\n- The quick\n  brown fox\n  jumps\n",
+		},
+		{
+			name: "preformatted text and tables",
+			html: "<pre>a  b\n  c</pre><table><tr><th>Key</th><th>Value</th></tr><tr><td>x</td><td>1</td></tr></table>",
+			want: "a  b\n  c\n\nKey\tValue\nx\t1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := dom.Parse(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body := dom.GetElementsByTagName(doc, "body")[0]
+			if got := RenderText(body, tt.opts); got != tt.want {
+				t.Errorf("RenderText()\nwant: %q\ngot : %q", tt.want, got)
+			}
+		})
+	}
+}